@@ -1,6 +1,11 @@
 // Package config configures the application on start, exports config, initialization, etc
 package config
 
+import "regexp"
+
+// slurmVersionRe matches a Slurm release, YY.MM with an optional patch level
+var slurmVersionRe = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?$`)
+
 // checkConfig checks the config for validity
 func checkConfig() error {
 	switch cfg.Logging.Encoding {
@@ -10,6 +15,20 @@ func checkConfig() error {
 		return ErrLoggingEncodingInvalid
 	}
 
+	// webhook checks
+	if cfg.WebhookAPI.Enabled && cfg.WebhookAPI.Service == "" {
+		return ErrWebhookAPIServiceNotSet
+	}
+
+	if cfg.WebhookAPI.Enabled && cfg.WebhookAPI.Namespace == "" {
+		return ErrWebhookAPINamespaceNotSet
+	}
+
+	// slurm version checks
+	if cfg.Slurm.Version != "" && !slurmVersionRe.MatchString(cfg.Slurm.Version) {
+		return ErrSlurmVersionInvalid
+	}
+
 	// slurmabler checks
 	if cfg.Slurm.Slurmabler.Image == "" {
 		return ErrSlurmSlurmablerImageNotSet
@@ -54,5 +73,20 @@ func checkConfig() error {
 		return ErrSlurmSlurmrestdImageNotSet
 	}
 
+	// login
+	if cfg.Slurm.Login.Image == "" {
+		return ErrSlurmLoginImageNotSet
+	}
+
+	// sssd
+	if cfg.Slurm.SSSD.Image == "" {
+		return ErrSlurmSSSDImageNotSet
+	}
+
+	// balloon
+	if cfg.Slurm.Balloon.Image == "" {
+		return ErrSlurmBalloonImageNotSet
+	}
+
 	return nil
 }
@@ -13,6 +13,9 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultSlurmVersion is the Slurm release of the published images, used when slurm.version is not set
+const DefaultSlurmVersion = "21.08"
+
 var cfg Config
 
 // Config is the CLI options wrapped in a struct
@@ -22,6 +25,9 @@ type Config struct {
 
 	Logging   Logging   `yaml:"logging"`
 	ProbesAPI ProbesAPI `yaml:"probes_api"`
+	PowerAPI  PowerAPI  `yaml:"power_api"`
+
+	WebhookAPI WebhookAPI `yaml:"webhook_api"`
 
 	Slurm Slurm `yaml:"slurm"`
 }
@@ -46,8 +52,26 @@ type ProbesAPI struct {
 	Port   uint16 `yaml:"port"`
 }
 
-// Slurm config
+// PowerAPI power saving API definition, URL is how slurmctld reaches the operator
+type PowerAPI struct {
+	Listen string `yaml:"listen"`
+	Port   uint16 `yaml:"port"`
+	URL    string `yaml:"url"`
+}
+
+// WebhookAPI admission webhook API definition, Service and Namespace name the Service the api server calls
+type WebhookAPI struct {
+	Enabled   bool   `yaml:"enabled"`
+	Listen    string `yaml:"listen"`
+	Port      uint16 `yaml:"port"`
+	Service   string `yaml:"service"`
+	Namespace string `yaml:"namespace"`
+}
+
+// Slurm config, Version is the Slurm release of the images
 type Slurm struct {
+	Version string `yaml:"version"`
+
 	Slurmabler   Slurmabler   `yaml:"slurmabler"`
 	Munged       Munged       `yaml:"munged"`
 	Slurmctld    Slurmctld    `yaml:"slurmctld"`
@@ -56,6 +80,9 @@ type Slurm struct {
 	MariaDB      MariaDB      `yaml:"mariadb"`
 	Slurmdbd     Slurmdbd     `yaml:"slurmdbd"`
 	Slurmrestd   Slurmrestd   `yaml:"slurmrestd"`
+	Login        Login        `yaml:"login"`
+	SSSD         SSSD         `yaml:"sssd"`
+	Balloon      Balloon      `yaml:"balloon"`
 }
 
 // Slurmabler config
@@ -100,6 +127,21 @@ type Slurmrestd struct {
 	Image string `yaml:"image"`
 }
 
+// Login config
+type Login struct {
+	Image string `yaml:"image"`
+}
+
+// SSSD config
+type SSSD struct {
+	Image string `yaml:"image"`
+}
+
+// Balloon config
+type Balloon struct {
+	Image string `yaml:"image"`
+}
+
 // NewConfig returns a Config struct that can be used to reference configuration
 // NewConfig does the following:
 //   - Runs initCLI (sets and read CLI switches)
@@ -127,6 +169,19 @@ func NewConfig(name string) (*Config, error) {
 	return &cfg, nil
 }
 
+// LoadConfig reads and checks the config file without CLI switches or logging, used by the render command
+func LoadConfig(name, cfgFile string) (*Config, error) {
+	if err := initConf(name, cfgFile); err != nil {
+		return nil, fmt.Errorf("config.LoadConfig: %w", err)
+	}
+
+	if err := checkConfig(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
 // initCLI initializes CLI switches
 func initCLI(config *Config) {
 	flag.StringVar(&config.ConfigFile, "config", "./config.yaml", "Path for the config.yaml configuration file")
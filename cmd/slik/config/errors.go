@@ -6,7 +6,12 @@ import "errors"
 var (
 	ErrLoggingEncodingInvalid = errors.New("logging.encoding must be either json or console")
 
+	// webhook
+	ErrWebhookAPIServiceNotSet   = errors.New("webhook_api.service not set")
+	ErrWebhookAPINamespaceNotSet = errors.New("webhook_api.namespace not set")
+
 	// slurm
+	ErrSlurmVersionInvalid                 = errors.New("slurm.version must be a Slurm release like 23.11")
 	ErrSlurmSlurmablerImageNotSet          = errors.New("slurm.slurmabler.image not set")
 	ErrSlurmSlurmablerServiceAccountNotSet = errors.New("slurm.slurmabler.service_account not set")
 	ErrSlurmMungedImageNotSet              = errors.New("slurm.munged.image not set")
@@ -16,4 +21,7 @@ var (
 	ErrSlurmMariaDBNotSet                  = errors.New("slurm.mariadb.image not set")
 	ErrSlurmSlurmdbdImageNotSet            = errors.New("slurm.slurmdbd.image not set")
 	ErrSlurmSlurmrestdImageNotSet          = errors.New("slurm.slurmrestd.image not set")
+	ErrSlurmLoginImageNotSet               = errors.New("slurm.login.image not set")
+	ErrSlurmSSSDImageNotSet                = errors.New("slurm.sssd.image not set")
+	ErrSlurmBalloonImageNotSet             = errors.New("slurm.balloon.image not set")
 )
@@ -16,11 +16,60 @@ func GetProbesAPIPort() uint16 {
 	return cfg.ProbesAPI.Port
 }
 
+// GetPowerAPIListen returns power api listen addr
+func GetPowerAPIListen() string {
+	return cfg.PowerAPI.Listen
+}
+
+// GetPowerAPIPort returns power api listen port
+func GetPowerAPIPort() uint16 {
+	return cfg.PowerAPI.Port
+}
+
+// GetPowerAPIURL returns the power api url used by slurmctld
+func GetPowerAPIURL() string {
+	return cfg.PowerAPI.URL
+}
+
+// GetWebhookAPIEnabled returns true if the admission webhook is served
+func GetWebhookAPIEnabled() bool {
+	return cfg.WebhookAPI.Enabled
+}
+
+// GetWebhookAPIListen returns webhook api listen addr
+func GetWebhookAPIListen() string {
+	return cfg.WebhookAPI.Listen
+}
+
+// GetWebhookAPIPort returns webhook api listen port
+func GetWebhookAPIPort() uint16 {
+	return cfg.WebhookAPI.Port
+}
+
+// GetWebhookAPIService returns the name of the Service of the webhook api
+func GetWebhookAPIService() string {
+	return cfg.WebhookAPI.Service
+}
+
+// GetWebhookAPINamespace returns the namespace of the webhook api Service and certificate Secret
+func GetWebhookAPINamespace() string {
+	return cfg.WebhookAPI.Namespace
+}
+
 // GetLoggingPath returns logging path
 func GetLoggingPath() string {
 	return cfg.Logging.Path
 }
 
+// GetSlurmVersion returns the Slurm release of the images, DefaultSlurmVersion if not set
+func GetSlurmVersion() string {
+	if cfg.Slurm.Version == "" {
+		return DefaultSlurmVersion
+	}
+
+	return cfg.Slurm.Version
+}
+
 // GetSlurmSlurmablerImage returns the slurmabler image
 func GetSlurmSlurmablerImage() string {
 	return cfg.Slurm.Slurmabler.Image
@@ -65,3 +114,18 @@ func GetSlurmSlurmdbdImage() string {
 func GetSlurmSlurmrestdImage() string {
 	return cfg.Slurm.Slurmrestd.Image
 }
+
+// GetSlurmLoginImage returns login image
+func GetSlurmLoginImage() string {
+	return cfg.Slurm.Login.Image
+}
+
+// GetSlurmSSSDImage returns sssd image
+func GetSlurmSSSDImage() string {
+	return cfg.Slurm.SSSD.Image
+}
+
+// GetSlurmBalloonImage returns the balloon pod image
+func GetSlurmBalloonImage() string {
+	return cfg.Slurm.Balloon.Image
+}
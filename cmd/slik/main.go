@@ -3,16 +3,22 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/vultr/slik/cmd/slik/config"
 	"github.com/vultr/slik/cmd/slik/metrics"
+	"github.com/vultr/slik/pkg/connectors"
 	"github.com/vultr/slik/pkg/helpers"
+	"github.com/vultr/slik/pkg/power"
 	"github.com/vultr/slik/pkg/probes"
 	"github.com/vultr/slik/pkg/reconciler"
+	"github.com/vultr/slik/pkg/webhook"
 
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -26,6 +32,16 @@ var (
 )
 
 func main() { //nolint
+	// offline render of the objects for a Slik manifest, see render
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := render(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	_, err := config.NewConfig(name)
 	if err != nil {
 		logger, _ := zap.NewDevelopment()
@@ -60,6 +76,38 @@ func main() { //nolint
 		log.Fatal(err)
 	}
 
+	log.With(
+		"context", name,
+	).Info("initializing power api")
+
+	powerAPI, err := power.NewPowerAPI(name, config.GetPowerAPIListen(), config.GetPowerAPIPort())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var webhookAPI *webhook.WebhookAPI
+	var webhookCS kubernetes.Interface
+	if config.GetWebhookAPIEnabled() {
+		log.With(
+			"context", name,
+		).Info("initializing webhook api")
+
+		webhookCS, err = connectors.GetKubernetesConn()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		cert, err := webhook.EnsureCertificates(webhookCS, config.GetWebhookAPINamespace(), config.GetWebhookAPIService())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		webhookAPI, err = webhook.NewWebhookAPI(name, config.GetWebhookAPIListen(), config.GetWebhookAPIPort(), cert)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	recon := reconciler.NewReconciler()
 
 	// run http probes api
@@ -84,6 +132,59 @@ func main() { //nolint
 		}
 	})
 
+	// run http power api, called by slurmctld for elastic nodes
+	g.Go(func() error {
+		for {
+			select {
+			case <-gCtx.Done():
+				log.With(
+					"context", name,
+				).Info("power: exited")
+
+				return nil
+			default:
+				log.With(
+					"context", name,
+				).Info("power: starting")
+
+				if err2 := powerAPI.Start(); err2 != nil {
+					return err2
+				}
+			}
+		}
+	})
+
+	// run https admission webhook api, called by the api server for Slik objects
+	if webhookAPI != nil {
+		g.Go(func() error {
+			for {
+				select {
+				case <-gCtx.Done():
+					log.With(
+						"context", name,
+					).Info("webhook: exited")
+
+					return nil
+				default:
+					log.With(
+						"context", name,
+					).Info("webhook: starting")
+
+					if err2 := webhookAPI.Start(); err2 != nil {
+						return err2
+					}
+				}
+			}
+		})
+
+		// the certificate expires while the operator runs, it is renewed before
+		g.Go(func() error {
+			webhookAPI.RenewCertificates(gCtx, webhookCS, config.GetWebhookAPINamespace(), config.GetWebhookAPIService())
+
+			return nil
+		})
+	}
+
 	// start reconcile loop
 	g.Go(func() error {
 		select {
@@ -121,6 +222,20 @@ func main() { //nolint
 			).Error(err)
 		}
 
+		if err := powerAPI.Shutdown(); err != nil {
+			log.With(
+				"context", name,
+			).Error(err)
+		}
+
+		if webhookAPI != nil {
+			if err := webhookAPI.Shutdown(); err != nil {
+				log.With(
+					"context", name,
+				).Error(err)
+			}
+		}
+
 		recon.Shutdown()
 
 		return nil
@@ -39,6 +39,12 @@ var (
 	// healthz checks
 	healthzSuccess *prometheus.GaugeVec
 	healthzError   *prometheus.GaugeVec
+
+	// pending slurm demand
+	slurmPendingJobs     *prometheus.GaugeVec
+	slurmPendingNodes    *prometheus.GaugeVec
+	slurmPendingCPUs     *prometheus.GaugeVec
+	slurmPendingMemoryMB *prometheus.GaugeVec
 )
 
 var mut sync.Mutex
@@ -155,6 +161,50 @@ func NewMetrics() {
 			"check",
 		},
 	)
+
+	slurmPendingJobs = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "slik_pending_jobs",
+			Help: "slurm jobs pending for resources",
+		},
+		[]string{
+			"cluster",
+			"partition",
+		},
+	)
+
+	slurmPendingNodes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "slik_pending_nodes",
+			Help: "nodes requested by slurm jobs pending for resources",
+		},
+		[]string{
+			"cluster",
+			"partition",
+		},
+	)
+
+	slurmPendingCPUs = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "slik_pending_cpus",
+			Help: "cpus requested by slurm jobs pending for resources",
+		},
+		[]string{
+			"cluster",
+			"partition",
+		},
+	)
+
+	slurmPendingMemoryMB = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "slik_pending_memory_mb",
+			Help: "memory in MB requested by slurm jobs pending for resources",
+		},
+		[]string{
+			"cluster",
+			"partition",
+		},
+	)
 }
 
 // SetVCDNServerVersion sets label version
@@ -249,3 +299,26 @@ func IncrementHealthzError(check string) {
 
 	healthzError.WithLabelValues(check).Inc()
 }
+
+// SetSlurmDemand sets the pending demand of a slurm partition
+func SetSlurmDemand(cluster, partition string, jobs, nodes, cpus, memoryMB int) {
+	mut.Lock()
+	defer mut.Unlock()
+
+	slurmPendingJobs.WithLabelValues(cluster, partition).Set(float64(jobs))
+	slurmPendingNodes.WithLabelValues(cluster, partition).Set(float64(nodes))
+	slurmPendingCPUs.WithLabelValues(cluster, partition).Set(float64(cpus))
+	slurmPendingMemoryMB.WithLabelValues(cluster, partition).Set(float64(memoryMB))
+}
+
+// ResetSlurmDemand removes the pending demand of every partition of a slurm cluster
+func ResetSlurmDemand(cluster string) {
+	mut.Lock()
+	defer mut.Unlock()
+
+	labels := prometheus.Labels{"cluster": cluster}
+	slurmPendingJobs.DeletePartialMatch(labels)
+	slurmPendingNodes.DeletePartialMatch(labels)
+	slurmPendingCPUs.DeletePartialMatch(labels)
+	slurmPendingMemoryMB.DeletePartialMatch(labels)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurm"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// render prints the objects slik would create for a Slik manifest as YAML, without a cluster:
+//
+//	slik render -f slik.yaml --nodes nodes.yaml
+//
+// nodes.yaml holds the Nodes, labeled like slurmabler does, and the ConfigMaps and Secrets the spec references.
+func render(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	file := flags.String("f", "", "Path of the Slik manifest")
+	nodes := flags.String("nodes", "", "Path of the Nodes, ConfigMaps and Secrets to seed the cluster with")
+	cfgFile := flags.String("config", "./config.yaml", "Path for the config.yaml configuration file")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return errors.New("render: -f is required")
+	}
+
+	// images of the pods
+	if _, err := config.LoadConfig(name, *cfgFile); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	var wl v1s.Slik
+	if err := yaml.UnmarshalStrict(data, &wl); err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+
+	// like the reconciler, a Slik without namespace runs in spec.namespace
+	if wl.Namespace == "" {
+		wl.Namespace = wl.Spec.Namespace
+	}
+
+	fixtures := []runtime.Object{}
+	if *nodes != "" {
+		if fixtures, err = readFixtures(*nodes); err != nil {
+			return err
+		}
+	}
+
+	objs, err := slurm.Render(&wl, fixtures)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFixtures decodes the documents of a multi-document YAML file
+func readFixtures(path string) ([]runtime.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fixtures := []runtime.Object{}
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(f))
+
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return fixtures, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		fixtures = append(fixtures, obj)
+	}
+}
@@ -72,6 +72,13 @@ func main() { //nolint
 	labels["slik.vultr.com/cores_per_socket"] = fmt.Sprintf("%d", labeler.CoresPerSocket)
 	labels["slik.vultr.com/threads_per_core"] = fmt.Sprintf("%d", labeler.ThreadsPerCore)
 	labels["slik.vultr.com/real_memory"] = fmt.Sprintf("%d", labeler.RealMemory)
+	labels["slik.vultr.com/gpus"] = fmt.Sprintf("%d", labeler.GPUs)
+
+	if labeler.GPUType != "" {
+		labels["slik.vultr.com/gpu_type"] = labeler.GPUType
+	} else {
+		delete(labels, "slik.vultr.com/gpu_type")
+	}
 
 	node.Labels = labels
 
@@ -91,6 +98,7 @@ func getNodeConfig() (*labeler.Labels, error) {
 	}
 
 	labeler := labeler.NewLabeler(string(out))
+	labeler.DetectGPUs("/")
 
 	return labeler, nil
 }
@@ -0,0 +1,16 @@
+#!/bin/bash
+set -euo pipefail
+
+mkdir -p /run/sshd
+
+# ssh sessions do not inherit the container environment, pam_env reads /etc/environment
+if [ -n "${SLURM_CONF_SERVER:-}" ]; then
+	echo "SLURM_CONF_SERVER=$SLURM_CONF_SERVER" >> /etc/environment
+fi
+
+exec /usr/sbin/sshd -D -e \
+	-o HostKey=/etc/ssh/hostkeys/ssh_host_ed25519_key \
+	-o HostKey=/etc/ssh/hostkeys/ssh_host_ecdsa_key \
+	-o AuthorizedKeysFile=/etc/ssh/authorized_keys/%u \
+	-o PasswordAuthentication=no \
+	-o PermitRootLogin=no
@@ -4,4 +4,9 @@ set -euo pipefail
 # will not work if container is not running as privileged + root
 #echo $HOSTNAME > /proc/sys/kernel/hostname
 
+# configless, slurm.conf is fetched from slurmctld
+if [ -n "${X_VULTR_SLURM_CONF_SERVER:-}" ]; then
+	exec slurmd -D -v --conf-server "$X_VULTR_SLURM_CONF_SERVER"
+fi
+
 slurmd -D -v -f /etc/slurm/slurm.conf
\ No newline at end of file
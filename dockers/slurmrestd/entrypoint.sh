@@ -1,4 +1,9 @@
 #!/bin/bash
 set -euo pipefail
 
-slurmrestd -v -f /etc/slurm/slurm.conf 0.0.0.0:6820
\ No newline at end of file
+# configless, slurm.conf is fetched from SLURM_CONF_SERVER
+if [ -n "${SLURM_CONF_SERVER:-}" ]; then
+	exec slurmrestd -v 0.0.0.0:6820
+fi
+
+slurmrestd -v -f /etc/slurm/slurm.conf 0.0.0.0:6820
@@ -0,0 +1,6 @@
+#!/bin/bash
+set -euo pipefail
+
+mkdir -p /var/lib/sss/pipes/private
+
+sssd -i --logger=stderr
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the checked-in CRDs")
+
+// root of the repository, holding tools.mod
+const root = "../../../.."
+
+// crds are the checked-in copies of the generated CRDs
+var crds = []string{
+	"crds/v1/crds.yaml",
+	"helm/slik/templates/crds.yaml",
+}
+
+// TestCRDs fails if the CRDs differ from the ones generated from the types, rerun with -update if expected
+func TestCRDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs controller-gen")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("go", "tool", "-modfile=tools.mod", "controller-gen",
+		"crd:maxDescLen=0", "paths=./pkg/api/types/v1/...", "output:crd:stdout")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("controller-gen: %v\n%s", err, stderr.String())
+	}
+
+	for _, crd := range crds {
+		path := filepath.Join(root, crd)
+		if *update {
+			if err := os.WriteFile(path, stdout.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(stdout.Bytes(), want) {
+			t.Errorf("%s is stale, run go generate ./pkg/api/types/v1", crd)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Package v1 holds the Slik and SlurmNodeSet custom resources of the hpc.vultr.com group. The deepcopy
+// functions and the CRDs in crds/v1 are generated from the types and their markers:
+//
+//	go generate ./pkg/api/types/v1
+//
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:Optional
+// +groupName=hpc.vultr.com
+package v1
+
+//go:generate go tool -modfile=../../../../tools.mod controller-gen object paths=.
+//go:generate go test -run TestCRDs -update
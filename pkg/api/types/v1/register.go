@@ -20,6 +20,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Slik{},
 		&SlikList{},
+		&SlurmNodeSet{},
+		&SlurmNodeSetList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -1,34 +1,387 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// to generate...
-// 1. install: go install sigs.k8s.io/controller-tools/cmd/controller-gen@v0.15.0
-// 2a. generate: controller-gen object paths=./spec/api/types/v1/slik.go
-// 2b. or: go generate ./...
-
-//go:generate controller-gen object paths=$GOFILE
-
+// +kubebuilder:validation:XValidation:rule="!self.slurmrestd || self.slurmdbd",message="slurmrestd requires slurmdbd"
 type SlikSpec struct {
-	Namespace  string `json:"namespace"`
-	Slurmdbd   bool   `json:"slurmdbd"`
-	Slurmrestd bool   `json:"slurmrestd"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="oldSelf == '' || self == oldSelf",message="namespace is immutable"
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:default=false
+	Slurmdbd bool `json:"slurmdbd"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:default=false
+	Slurmrestd bool `json:"slurmrestd"`
+
+	// Configless slurmd and clients fetch slurm.conf from slurmctld
+	// +kubebuilder:default=false
+	Configless bool `json:"configless"`
 
+	// +kubebuilder:default={}
 	MariaDB MariaDB `json:"mariadb"`
+	// +kubebuilder:default={}
+	Slurmctld Slurmctld `json:"slurmctld"`
+	// +kubebuilder:default={}
+	Slurmd Slurmd `json:"slurmd"`
+
+	SharedVolumes []SharedVolume `json:"sharedVolumes,omitempty"`
+
+	// +kubebuilder:default={}
+	Login Login `json:"login"`
+
+	// +kubebuilder:default={}
+	Identity Identity `json:"identity"`
+
+	// +kubebuilder:default={}
+	Drain Drain `json:"drain"`
+
+	// Paused stops the operator from changing the cluster, manual edits are kept until it is unpaused
+	// +kubebuilder:default=false
+	Paused bool `json:"paused,omitempty"`
+
+	// Maintenance drains every slurm node and sets the partitions DOWN, slurmctld keeps running
+	// +kubebuilder:default=false
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	// +kubebuilder:default={}
+	Elastic Elastic `json:"elastic"`
+
+	// +kubebuilder:default={}
+	Autoscaling Autoscaling `json:"autoscaling"`
+
+	// ComputePools run slurmd in pods of a fixed size instead of one slurmd per kubernetes node
+	ComputePools []ComputePool `json:"computePools,omitempty"`
+
+	// NodeFeatures kubernetes node label keys, their values become slurm Features of the nodes
+	NodeFeatures []string `json:"nodeFeatures,omitempty"`
+
+	Topology Topology `json:"topology"`
+
+	// +kubebuilder:default={}
+	Cgroups Cgroups `json:"cgroups"`
+
+	// SlurmConf and SlurmdbdConf add parameters slik does not model, keys slik renders itself are rejected
+	SlurmConf    ConfOverrides `json:"slurmConf"`
+	SlurmdbdConf ConfOverrides `json:"slurmdbdConf"`
+
+	Scripts Scripts `json:"scripts"`
 }
 
 type MariaDB struct {
-	StorageSize  string `json:"storage_size"`
+	// +kubebuilder:default="50G"
+	// +kubebuilder:validation:Pattern=`^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$`
+	StorageSize string `json:"storage_size"`
+	// +kubebuilder:default="vultr-block-storage-hdd-retain"
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="storage_class is immutable"
 	StorageClass string `json:"storage_class"`
 }
 
+type Slurmctld struct {
+	// HighAvailability runs a primary and backup slurmctld sharing StateSaveLocation
+	// +kubebuilder:default=false
+	HighAvailability bool `json:"highAvailability"`
+	// +kubebuilder:default="1Gi"
+	// +kubebuilder:validation:Pattern=`^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$`
+	StateStorageSize string `json:"stateStorageSize,omitempty"`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="stateStorageClass is immutable"
+	StateStorageClass string `json:"stateStorageClass,omitempty"`
+}
+
+// Slurmd settings of the slurmd SlurmNodeSet
+type Slurmd struct {
+	// MaxUnavailable slurmd pods drained and replaced at the same time on updates, defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+	// MaxUnavailablePerPartition slurmd pods of one slurm partition drained and replaced at the same time
+	// on updates, not limited if 0
+	// +kubebuilder:validation:Minimum=0
+	MaxUnavailablePerPartition int32 `json:"maxUnavailablePerPartition,omitempty"`
+}
+
+// Components a shared volume can be mounted into
+const (
+	ComponentSlurmd     string = "slurmd"
+	ComponentSlurmctld  string = "slurmctld"
+	ComponentSlurmrestd string = "slurmrestd"
+	ComponentToolbox    string = "toolbox"
+	ComponentLogin      string = "login"
+)
+
+// SharedVolume is a filesystem mounted at the same path in the selected components.
+// Exactly one of ClaimName, NFS or HostPath must be set.
+type SharedVolume struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// +kubebuilder:validation:Required
+	MountPath string `json:"mountPath"`
+	// +kubebuilder:default=false
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Components to mount into, all components when empty
+	// +kubebuilder:validation:items:Enum=slurmd;slurmctld;slurmrestd;toolbox;login
+	Components []string `json:"components,omitempty"`
+
+	ClaimName string                       `json:"claimName,omitempty"`
+	NFS       *corev1.NFSVolumeSource      `json:"nfs,omitempty"`
+	HostPath  *corev1.HostPathVolumeSource `json:"hostPath,omitempty"`
+}
+
+// Login ssh login nodes for users
+type Login struct {
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ServiceType of the ssh service, LoadBalancer or NodePort
+	// +kubebuilder:default="LoadBalancer"
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort;ClusterIP
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	AuthorizedKeys []AuthorizedKeys `json:"authorizedKeys,omitempty"`
+}
+
+// AuthorizedKeys authorized_keys of a login user, exactly one of the refs must be set
+type AuthorizedKeys struct {
+	// +kubebuilder:validation:Required
+	User string `json:"user"`
+
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+// Identity modes
+const (
+	IdentityModeNone  string = ""
+	IdentityModeLDAP  string = "ldap"
+	IdentityModeLocal string = "local"
+)
+
+// Identity user and group resolution shared by all slurm pods
+type Identity struct {
+	// Mode is ldap (sssd sidecar), local (static passwd/group) or empty to use the image accounts
+	// +kubebuilder:validation:Enum="";ldap;local
+	Mode string `json:"mode,omitempty"`
+
+	LDAP  LDAPIdentity  `json:"ldap,omitempty"`
+	Local LocalIdentity `json:"local,omitempty"`
+}
+
+// LDAPIdentity ldap directory used by sssd
+type LDAPIdentity struct {
+	URI    string `json:"uri"`
+	BaseDN string `json:"baseDN"`
+	BindDN string `json:"bindDN,omitempty"`
+
+	BindPasswordSecretRef *corev1.SecretKeySelector `json:"bindPasswordSecretRef,omitempty"`
+	CACertSecretRef       *corev1.SecretKeySelector `json:"caCertSecretRef,omitempty"`
+}
+
+// LocalIdentity static accounts for testing, the ConfigMap holds passwd and group keys
+type LocalIdentity struct {
+	ConfigMap string `json:"configMap"`
+}
+
+// Drain settings for kubernetes nodes that are cordoned, tainted or annotated with slik.vultr.com/drain
+type Drain struct {
+	// TimeoutSeconds to wait for running jobs before the node is removed anyway, 0 uses the default
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// Elastic slurm CLOUD nodes powered up on demand by slurm power saving. Their slurmd pods run on
+// kubernetes nodes labeled slik.vultr.com/elastic=<name>, which the cluster autoscaler can add.
+type Elastic struct {
+	// Nodes maximum number of elastic slurm nodes, 0 disables power saving
+	// +kubebuilder:validation:Minimum=0
+	Nodes int32 `json:"nodes,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	CPUs int32 `json:"cpus,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	RealMemory int32 `json:"realMemory,omitempty"`
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	ThreadsPerCore int32 `json:"threadsPerCore,omitempty"`
+
+	// SuspendTime idle seconds before a node is powered down
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	SuspendTime int32 `json:"suspendTime,omitempty"`
+	// ResumeTimeout seconds for a powered up node to register before slurm marks it down
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	ResumeTimeout int32 `json:"resumeTimeout,omitempty"`
+}
+
+// Autoscaling runs placeholder "balloon" pods sized to a node for the pending slurm demand, so the
+// cluster autoscaler adds nodes that slurmabler labels and slik adds to slurm.conf
+type Autoscaling struct {
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+
+	// MaxNodes upper bound of balloon pods
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	MaxNodes int32 `json:"maxNodes,omitempty"`
+
+	// NodeCPUs and NodeRealMemory (MB) of a node added by the autoscaler, pending cpus and memory are divided by them
+	// +kubebuilder:validation:Minimum=0
+	NodeCPUs int32 `json:"nodeCPUs,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	NodeRealMemory int32 `json:"nodeRealMemory,omitempty"`
+
+	// BalloonResources requested by each balloon pod, just below the allocatable resources of a node
+	BalloonResources corev1.ResourceList `json:"balloonResources,omitempty"`
+	NodeSelector     map[string]string   `json:"nodeSelector,omitempty"`
+}
+
+// ComputePool slurmd pods with stable hostnames in a StatefulSet, sharing kubernetes nodes with other
+// workloads. The slurm CPUs and RealMemory of the nodes are the cpu and memory limits of the pods.
+type ComputePool struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Resources of the slurmd container, cpu and memory limits are required
+	// +kubebuilder:validation:Required
+	Resources    corev1.ResourceRequirements `json:"resources"`
+	NodeSelector map[string]string           `json:"nodeSelector,omitempty"`
+}
+
+// Topology generates topology.conf for topology/tree from kubernetes node labels
+type Topology struct {
+	// Labels node label keys from the top of the tree to the leaf switches, e.g. zone, rack
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Cgroups confines jobs to their allocated cores, memory and devices with the cgroup plugins
+type Cgroups struct {
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default=true
+	ConstrainCores bool `json:"constrainCores"`
+	// +kubebuilder:default=true
+	ConstrainRAMSpace bool `json:"constrainRAMSpace"`
+	// +kubebuilder:default=true
+	ConstrainDevices bool `json:"constrainDevices"`
+}
+
+// ConfOverrides extra parameters and included files of a slurm configuration file
+type ConfOverrides struct {
+	// Extra lines appended in order
+	Extra []ConfParameter `json:"extra,omitempty"`
+
+	// Includes ConfigMap keys copied next to the file and loaded with Include
+	Includes []ConfInclude `json:"includes,omitempty"`
+}
+
+// ConfParameter a Key=Value line
+type ConfParameter struct {
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+	// +kubebuilder:validation:Required
+	Value string `json:"value"`
+}
+
+// ConfInclude a key of a ConfigMap in the namespace of the cluster
+type ConfInclude struct {
+	// +kubebuilder:validation:Required
+	ConfigMap string `json:"configMap"`
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
+// Scripts prolog, epilog and job submit scripts from ConfigMaps in the namespace of the cluster
+type Scripts struct {
+	// Prolog, Epilog and TaskProlog run by slurmd
+	Prolog     *ScriptRef `json:"prolog,omitempty"`
+	Epilog     *ScriptRef `json:"epilog,omitempty"`
+	TaskProlog *ScriptRef `json:"taskProlog,omitempty"`
+
+	// PrologSlurmctld and EpilogSlurmctld run by slurmctld
+	PrologSlurmctld *ScriptRef `json:"prologSlurmctld,omitempty"`
+	EpilogSlurmctld *ScriptRef `json:"epilogSlurmctld,omitempty"`
+
+	// JobSubmit is the job_submit.lua of the lua job submit plugin
+	JobSubmit *ScriptRef `json:"jobSubmit,omitempty"`
+}
+
+// ScriptRef a key of a ConfigMap holding a script
+type ScriptRef struct {
+	// +kubebuilder:validation:Required
+	ConfigMap string `json:"configMap"`
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
 type SlikStatus struct {
 	State string `json:"state"`
+
+	// Draining nodes that are drained in slurm and still have a slurmd
+	Draining []DrainingNode `json:"draining,omitempty"`
+
+	// SlurmConfChecksum of the slurm ConfigMap last applied with scontrol reconfigure
+	SlurmConfChecksum string `json:"slurmConfChecksum,omitempty"`
+
+	// PendingChanges of a cluster annotated for dry-run, computed instead of applied
+	PendingChanges []PendingChange `json:"pendingChanges,omitempty"`
+
+	// Paused the spec is not reconciled
+	Paused bool `json:"paused,omitempty"`
+
+	// Maintenance progress of spec.maintenance, DRAINING while jobs are running and DRAINED after
+	Maintenance string `json:"maintenance,omitempty"`
 }
 
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// Maintenance states
+const (
+	MaintenanceDraining string = "DRAINING"
+	MaintenanceDrained  string = "DRAINED"
+)
+
+// Pending change actions
+const (
+	PendingActionCreate string = "create"
+	PendingActionUpdate string = "update"
+	PendingActionDelete string = "delete"
+)
+
+// PendingChange an object a reconcile would create, update or delete
+type PendingChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+
+	// Restart the pods of the workload would be replaced
+	Restart bool `json:"restart,omitempty"`
+	// Checksums annotations of the pod template that changed, the configuration causing the restart
+	Checksums []string `json:"checksums,omitempty"`
+}
+
+// DrainingNode a kubernetes node whose slurm node is being drained
+type DrainingNode struct {
+	Node      string      `json:"node"`
+	Reason    string      `json:"reason"`
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=slik
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Paused",type=boolean,JSONPath=`.spec.paused`
+// +kubebuilder:printcolumn:name="Maintenance",type=string,JSONPath=`.status.maintenance`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:validation:XValidation:rule="!has(self.spec) || !self.spec.slurmctld.highAvailability || size(self.metadata.name) <= 42",message="name must be no more than 42 characters with slurmctld.highAvailability, the slurmctld StatefulSet is named after it"
+// +kubebuilder:validation:XValidation:rule="!has(self.spec) || !self.spec.slurmdbd || size(self.metadata.name) <= 44",message="name must be no more than 44 characters with slurmdbd, the mariadb StatefulSet is named after it"
 type Slik struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -37,29 +390,10 @@ type Slik struct {
 	Status SlikStatus `json:"status,omitempty"`
 }
 
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
 type SlikList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
 
 	Items []Slik `json:"items"`
 }
-
-// DeepCopyInto copies all properties of this object into another object of the
-// same type that is provided as a pointer.
-func (in *Slik) DeepCopyInto(out *Slik) {
-	out.TypeMeta = in.TypeMeta
-	out.ObjectMeta = in.ObjectMeta
-	out.Spec = SlikSpec{
-		Namespace:  in.Spec.Namespace,
-		Slurmdbd:   in.Spec.Slurmdbd,
-		Slurmrestd: in.Spec.Slurmrestd,
-		MariaDB: MariaDB{
-			StorageSize:  in.Spec.MariaDB.StorageSize,
-			StorageClass: in.Spec.MariaDB.StorageClass,
-		},
-	}
-	out.Status = SlikStatus{
-		State: in.Status.State,
-	}
-}
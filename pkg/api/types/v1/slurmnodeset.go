@@ -0,0 +1,72 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmNodeSetSpec one slurmd pod per selected kubernetes node
+type SlurmNodeSetSpec struct {
+	// Cluster name of the Slik the slurmd pods belong to
+	// +kubebuilder:validation:Required
+	Cluster string `json:"cluster"`
+
+	// NodeSelector limits the kubernetes nodes, all nodes labeled by slurmabler are used if empty
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Template of the slurmd pods, validated by the api server when the pods are created
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Template corev1.PodTemplateSpec `json:"template"`
+	// +kubebuilder:default={}
+	UpdateStrategy SlurmNodeSetUpdateStrategy `json:"updateStrategy"`
+}
+
+// SlurmNodeSetUpdateStrategy rolling update of slurmd pods, nodes are drained before their pod is replaced
+type SlurmNodeSetUpdateStrategy struct {
+	// MaxUnavailable slurmd pods drained or restarting at the same time, defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+	// MaxUnavailablePerPartition slurmd pods of one slurm partition drained or restarting at the same time,
+	// not limited if 0
+	// +kubebuilder:validation:Minimum=0
+	MaxUnavailablePerPartition int32 `json:"maxUnavailablePerPartition,omitempty"`
+}
+
+type SlurmNodeSetStatus struct {
+	// Revision hash of the current pod template
+	Revision string `json:"revision,omitempty"`
+
+	Nodes        int32 `json:"nodes"`
+	ReadyNodes   int32 `json:"readyNodes"`
+	UpdatedNodes int32 `json:"updatedNodes"`
+
+	// Updating kubernetes nodes whose slurmd is drained for replacement
+	Updating []string `json:"updating,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=sns
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster`
+// +kubebuilder:printcolumn:name="Nodes",type=integer,JSONPath=`.status.nodes`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyNodes`
+// +kubebuilder:printcolumn:name="Updated",type=integer,JSONPath=`.status.updatedNodes`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type SlurmNodeSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmNodeSetSpec   `json:"spec,omitempty"`
+	Status SlurmNodeSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type SlurmNodeSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SlurmNodeSet `json:"items"`
+}
@@ -5,9 +5,416 @@
 package v1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizedKeys) DeepCopyInto(out *AuthorizedKeys) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizedKeys.
+func (in *AuthorizedKeys) DeepCopy() *AuthorizedKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizedKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Autoscaling) DeepCopyInto(out *Autoscaling) {
+	*out = *in
+	if in.BalloonResources != nil {
+		in, out := &in.BalloonResources, &out.BalloonResources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Autoscaling.
+func (in *Autoscaling) DeepCopy() *Autoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Autoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cgroups) DeepCopyInto(out *Cgroups) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cgroups.
+func (in *Cgroups) DeepCopy() *Cgroups {
+	if in == nil {
+		return nil
+	}
+	out := new(Cgroups)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComputePool) DeepCopyInto(out *ComputePool) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComputePool.
+func (in *ComputePool) DeepCopy() *ComputePool {
+	if in == nil {
+		return nil
+	}
+	out := new(ComputePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfInclude) DeepCopyInto(out *ConfInclude) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfInclude.
+func (in *ConfInclude) DeepCopy() *ConfInclude {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfInclude)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfOverrides) DeepCopyInto(out *ConfOverrides) {
+	*out = *in
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make([]ConfParameter, len(*in))
+		copy(*out, *in)
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]ConfInclude, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfOverrides.
+func (in *ConfOverrides) DeepCopy() *ConfOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfParameter) DeepCopyInto(out *ConfParameter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfParameter.
+func (in *ConfParameter) DeepCopy() *ConfParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Drain) DeepCopyInto(out *Drain) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Drain.
+func (in *Drain) DeepCopy() *Drain {
+	if in == nil {
+		return nil
+	}
+	out := new(Drain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainingNode) DeepCopyInto(out *DrainingNode) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainingNode.
+func (in *DrainingNode) DeepCopy() *DrainingNode {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainingNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Elastic) DeepCopyInto(out *Elastic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Elastic.
+func (in *Elastic) DeepCopy() *Elastic {
+	if in == nil {
+		return nil
+	}
+	out := new(Elastic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Identity) DeepCopyInto(out *Identity) {
+	*out = *in
+	in.LDAP.DeepCopyInto(&out.LDAP)
+	out.Local = in.Local
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Identity.
+func (in *Identity) DeepCopy() *Identity {
+	if in == nil {
+		return nil
+	}
+	out := new(Identity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPIdentity) DeepCopyInto(out *LDAPIdentity) {
+	*out = *in
+	if in.BindPasswordSecretRef != nil {
+		in, out := &in.BindPasswordSecretRef, &out.BindPasswordSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CACertSecretRef != nil {
+		in, out := &in.CACertSecretRef, &out.CACertSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPIdentity.
+func (in *LDAPIdentity) DeepCopy() *LDAPIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalIdentity) DeepCopyInto(out *LocalIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalIdentity.
+func (in *LocalIdentity) DeepCopy() *LocalIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Login) DeepCopyInto(out *Login) {
+	*out = *in
+	if in.AuthorizedKeys != nil {
+		in, out := &in.AuthorizedKeys, &out.AuthorizedKeys
+		*out = make([]AuthorizedKeys, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Login.
+func (in *Login) DeepCopy() *Login {
+	if in == nil {
+		return nil
+	}
+	out := new(Login)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MariaDB) DeepCopyInto(out *MariaDB) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MariaDB.
+func (in *MariaDB) DeepCopy() *MariaDB {
+	if in == nil {
+		return nil
+	}
+	out := new(MariaDB)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingChange) DeepCopyInto(out *PendingChange) {
+	*out = *in
+	if in.Checksums != nil {
+		in, out := &in.Checksums, &out.Checksums
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingChange.
+func (in *PendingChange) DeepCopy() *PendingChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScriptRef) DeepCopyInto(out *ScriptRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScriptRef.
+func (in *ScriptRef) DeepCopy() *ScriptRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ScriptRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scripts) DeepCopyInto(out *Scripts) {
+	*out = *in
+	if in.Prolog != nil {
+		in, out := &in.Prolog, &out.Prolog
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.Epilog != nil {
+		in, out := &in.Epilog, &out.Epilog
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.TaskProlog != nil {
+		in, out := &in.TaskProlog, &out.TaskProlog
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.PrologSlurmctld != nil {
+		in, out := &in.PrologSlurmctld, &out.PrologSlurmctld
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.EpilogSlurmctld != nil {
+		in, out := &in.EpilogSlurmctld, &out.EpilogSlurmctld
+		*out = new(ScriptRef)
+		**out = **in
+	}
+	if in.JobSubmit != nil {
+		in, out := &in.JobSubmit, &out.JobSubmit
+		*out = new(ScriptRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scripts.
+func (in *Scripts) DeepCopy() *Scripts {
+	if in == nil {
+		return nil
+	}
+	out := new(Scripts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolume) DeepCopyInto(out *SharedVolume) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NFS != nil {
+		in, out := &in.NFS, &out.NFS
+		*out = new(corev1.NFSVolumeSource)
+		**out = **in
+	}
+	if in.HostPath != nil {
+		in, out := &in.HostPath, &out.HostPath
+		*out = new(corev1.HostPathVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolume.
+func (in *SharedVolume) DeepCopy() *SharedVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Slik) DeepCopyInto(out *Slik) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Slik.
 func (in *Slik) DeepCopy() *Slik {
 	if in == nil {
@@ -57,3 +464,247 @@ func (in *SlikList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlikSpec) DeepCopyInto(out *SlikSpec) {
+	*out = *in
+	out.MariaDB = in.MariaDB
+	out.Slurmctld = in.Slurmctld
+	out.Slurmd = in.Slurmd
+	if in.SharedVolumes != nil {
+		in, out := &in.SharedVolumes, &out.SharedVolumes
+		*out = make([]SharedVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Login.DeepCopyInto(&out.Login)
+	in.Identity.DeepCopyInto(&out.Identity)
+	out.Drain = in.Drain
+	out.Elastic = in.Elastic
+	in.Autoscaling.DeepCopyInto(&out.Autoscaling)
+	if in.ComputePools != nil {
+		in, out := &in.ComputePools, &out.ComputePools
+		*out = make([]ComputePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeFeatures != nil {
+		in, out := &in.NodeFeatures, &out.NodeFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Topology.DeepCopyInto(&out.Topology)
+	out.Cgroups = in.Cgroups
+	in.SlurmConf.DeepCopyInto(&out.SlurmConf)
+	in.SlurmdbdConf.DeepCopyInto(&out.SlurmdbdConf)
+	in.Scripts.DeepCopyInto(&out.Scripts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlikSpec.
+func (in *SlikSpec) DeepCopy() *SlikSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlikSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlikStatus) DeepCopyInto(out *SlikStatus) {
+	*out = *in
+	if in.Draining != nil {
+		in, out := &in.Draining, &out.Draining
+		*out = make([]DrainingNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]PendingChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlikStatus.
+func (in *SlikStatus) DeepCopy() *SlikStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlikStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmNodeSet) DeepCopyInto(out *SlurmNodeSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmNodeSet.
+func (in *SlurmNodeSet) DeepCopy() *SlurmNodeSet {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmNodeSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmNodeSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmNodeSetList) DeepCopyInto(out *SlurmNodeSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmNodeSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmNodeSetList.
+func (in *SlurmNodeSetList) DeepCopy() *SlurmNodeSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmNodeSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmNodeSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmNodeSetSpec) DeepCopyInto(out *SlurmNodeSetSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	out.UpdateStrategy = in.UpdateStrategy
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmNodeSetSpec.
+func (in *SlurmNodeSetSpec) DeepCopy() *SlurmNodeSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmNodeSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmNodeSetStatus) DeepCopyInto(out *SlurmNodeSetStatus) {
+	*out = *in
+	if in.Updating != nil {
+		in, out := &in.Updating, &out.Updating
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmNodeSetStatus.
+func (in *SlurmNodeSetStatus) DeepCopy() *SlurmNodeSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmNodeSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmNodeSetUpdateStrategy) DeepCopyInto(out *SlurmNodeSetUpdateStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmNodeSetUpdateStrategy.
+func (in *SlurmNodeSetUpdateStrategy) DeepCopy() *SlurmNodeSetUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmNodeSetUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Slurmctld) DeepCopyInto(out *Slurmctld) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Slurmctld.
+func (in *Slurmctld) DeepCopy() *Slurmctld {
+	if in == nil {
+		return nil
+	}
+	out := new(Slurmctld)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Slurmd) DeepCopyInto(out *Slurmd) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Slurmd.
+func (in *Slurmd) DeepCopy() *Slurmd {
+	if in == nil {
+		return nil
+	}
+	out := new(Slurmd)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Topology) DeepCopyInto(out *Topology) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
+func (in *Topology) DeepCopy() *Topology {
+	if in == nil {
+		return nil
+	}
+	out := new(Topology)
+	in.DeepCopyInto(out)
+	return out
+}
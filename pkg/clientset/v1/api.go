@@ -12,6 +12,7 @@ import (
 
 type V1Interface interface {
 	Slik(ctx context.Context) SlikInterface
+	SlurmNodeSet(ctx context.Context, namespace string) SlurmNodeSetInterface
 }
 
 type V1Client struct {
@@ -39,3 +40,12 @@ func (c *V1Client) Slik(ctx context.Context) SlikInterface {
 		ctx:        ctx,
 	}
 }
+
+// SlurmNodeSet returns a client for the SlurmNodeSets of a namespace, all namespaces if empty
+func (c *V1Client) SlurmNodeSet(ctx context.Context, namespace string) SlurmNodeSetInterface {
+	return &slurmNodeSetClient{
+		restClient: c.restClient,
+		ctx:        ctx,
+		namespace:  namespace,
+	}
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/vultr/slik/pkg/api/types/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+type SlurmNodeSetInterface interface {
+	List(opts metav1.ListOptions) (*v1.SlurmNodeSetList, error)
+	Get(name string, options metav1.GetOptions) (*v1.SlurmNodeSet, error)
+	Create(*v1.SlurmNodeSet) (*v1.SlurmNodeSet, error)
+	Update(nodeSet *v1.SlurmNodeSet, options metav1.UpdateOptions) (*v1.SlurmNodeSet, error)
+	UpdateStatus(nodeSet *v1.SlurmNodeSet, options metav1.UpdateOptions) (*v1.SlurmNodeSet, error)
+	Delete(name string, options metav1.DeleteOptions) error
+}
+
+type slurmNodeSetClient struct {
+	restClient rest.Interface
+	ctx        context.Context
+	namespace  string
+}
+
+func (c *slurmNodeSetClient) List(opts metav1.ListOptions) (*v1.SlurmNodeSetList, error) {
+	result := v1.SlurmNodeSetList{}
+
+	err := c.restClient.
+		Get().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(c.ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *slurmNodeSetClient) Get(name string, opts metav1.GetOptions) (*v1.SlurmNodeSet, error) {
+	result := v1.SlurmNodeSet{}
+
+	err := c.restClient.
+		Get().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(c.ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *slurmNodeSetClient) Create(nodeSet *v1.SlurmNodeSet) (*v1.SlurmNodeSet, error) {
+	result := v1.SlurmNodeSet{}
+
+	err := c.restClient.
+		Post().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		Body(nodeSet).
+		Do(c.ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *slurmNodeSetClient) Update(nodeSet *v1.SlurmNodeSet, options metav1.UpdateOptions) (*v1.SlurmNodeSet, error) {
+	result := v1.SlurmNodeSet{}
+
+	err := c.restClient.Put().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		Name(nodeSet.Name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Body(nodeSet).
+		Do(c.ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *slurmNodeSetClient) UpdateStatus(nodeSet *v1.SlurmNodeSet, options metav1.UpdateOptions) (*v1.SlurmNodeSet, error) {
+	result := v1.SlurmNodeSet{}
+
+	err := c.restClient.Put().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		Name(nodeSet.Name).
+		SubResource("status").
+		VersionedParams(&options, scheme.ParameterCodec).
+		Body(nodeSet).
+		Do(c.ctx).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *slurmNodeSetClient) Delete(name string, options metav1.DeleteOptions) error {
+	return c.restClient.Delete().
+		Namespace(c.namespace).
+		Resource("slurmnodesets").
+		Name(name).
+		Body(&options).
+		Do(c.ctx).
+		Error()
+}
@@ -25,6 +25,11 @@ func GetKubernetesConn() (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
+// GetKubernetesConfig returns the in cluster rest config, used for pod exec
+func GetKubernetesConfig() (*rest.Config, error) {
+	return rest.InClusterConfig()
+}
+
 // GetSlikClientset returns a slik clientset to interact with the k8s cluster
 func GetSlikClientset() (*client.V1Client, error) {
 	if err := api.AddToScheme(scheme.Scheme); err != nil {
@@ -0,0 +1,9 @@
+package hostkeys
+
+const (
+	// KeyTypeEd25519 ed25519 host key type
+	KeyTypeEd25519 string = "ed25519"
+
+	// KeyTypeECDSA ecdsa host key type
+	KeyTypeECDSA string = "ecdsa"
+)
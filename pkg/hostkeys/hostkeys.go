@@ -0,0 +1,59 @@
+package hostkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeys ssh host keys keyed by their file name in /etc/ssh
+type HostKeys map[string][]byte
+
+// NewHostKeys creates a new set of ed25519 and ecdsa ssh host keys
+func NewHostKeys() (HostKeys, error) {
+	keys := HostKeys{}
+
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := keys.add(KeyTypeEd25519, ed); err != nil {
+		return nil, err
+	}
+
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := keys.add(KeyTypeECDSA, ec); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (k HostKeys) add(keyType string, key crypto.Signer) error {
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return err
+	}
+
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("ssh_host_%s_key", keyType)
+	k[name] = pem.EncodeToMemory(block)
+	k[name+".pub"] = ssh.MarshalAuthorizedKey(pub)
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package labeler
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	nvidiaVendor = "0x10de"
+)
+
+var (
+	nvidiaDevice = regexp.MustCompile(`^nvidia[0-9]+$`)
+
+	// PCI classes of VGA and 3D controllers
+	gpuClasses = []string{"0x0300", "0x0302"}
+
+	// words of the model name that do not tell GPUs apart
+	gpuModelNoise = map[string]bool{"nvidia": true, "geforce": true, "tesla": true}
+)
+
+// DetectGPUs sets the number of NVIDIA GPUs from the device nodes below root, falling back to the PCI
+// devices in sysfs, and their type from the driver. root is / on a node, a fixture directory in tests.
+func (l *Labels) DetectGPUs(root string) {
+	log := zap.L().Sugar()
+
+	l.GPUs = countDeviceNodes(root)
+	if l.GPUs == 0 {
+		l.GPUs = countPCIGPUs(root)
+	}
+
+	if l.GPUs == 0 {
+		return
+	}
+
+	models, err := filepath.Glob(filepath.Join(root, "proc/driver/nvidia/gpus/*/information"))
+	if err != nil {
+		log.Error(err)
+
+		return
+	}
+
+	for _, model := range models {
+		data, err := os.ReadFile(model)
+		if err != nil {
+			log.Error(err)
+
+			continue
+		}
+
+		if gpuType := parseGPUModel(string(data)); gpuType != "" {
+			l.GPUType = gpuType
+
+			return
+		}
+	}
+}
+
+// countDeviceNodes counts /dev/nvidia<n>, the control devices like nvidiactl are skipped
+func countDeviceNodes(root string) int {
+	entries, err := os.ReadDir(filepath.Join(root, "dev"))
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if nvidiaDevice.MatchString(entry.Name()) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// countPCIGPUs counts NVIDIA display controllers in sysfs, found even before the driver is loaded
+func countPCIGPUs(root string) int {
+	devices, err := filepath.Glob(filepath.Join(root, "sys/bus/pci/devices/*"))
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, device := range devices {
+		vendor, err := os.ReadFile(filepath.Join(device, "vendor"))
+		if err != nil || strings.TrimSpace(string(vendor)) != nvidiaVendor {
+			continue
+		}
+
+		class, err := os.ReadFile(filepath.Join(device, "class"))
+		if err != nil {
+			continue
+		}
+
+		for _, prefix := range gpuClasses {
+			if strings.HasPrefix(strings.TrimSpace(string(class)), prefix) {
+				count++
+
+				break
+			}
+		}
+	}
+
+	return count
+}
+
+// parseGPUModel turns the Model line of the driver information, e.g. NVIDIA A100-SXM4-40GB, into a
+// gres type usable as a label value, e.g. a100
+func parseGPUModel(info string) string {
+	for line := range strings.Lines(info) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "Model" {
+			continue
+		}
+
+		words := []string{}
+		for _, word := range strings.Fields(strings.ToLower(value)) {
+			if !gpuModelNoise[word] {
+				words = append(words, word)
+			}
+		}
+
+		// the variant after the first dash, e.g. -SXM4-40GB, is not part of the type
+		gpuType, _, _ := strings.Cut(strings.Join(words, "_"), "-")
+
+		return strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+				return r
+			}
+
+			return -1
+		}, gpuType)
+	}
+
+	return ""
+}
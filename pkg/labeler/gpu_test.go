@@ -0,0 +1,37 @@
+package labeler
+
+import "testing"
+
+func TestDetectGPUs(t *testing.T) {
+	tests := []struct {
+		root    string
+		gpus    int
+		gpuType string
+	}{
+		{root: "testdata/devices", gpus: 2, gpuType: "a100"},
+		{root: "testdata/sysfs", gpus: 2},
+		{root: "testdata/none"},
+	}
+
+	for _, test := range tests {
+		var labels Labels
+		labels.DetectGPUs(test.root)
+
+		if labels.GPUs != test.gpus || labels.GPUType != test.gpuType {
+			t.Fatalf("%s: expected %d %q, got %d %q", test.root, test.gpus, test.gpuType, labels.GPUs, labels.GPUType)
+		}
+	}
+}
+
+func TestParseGPUModel(t *testing.T) {
+	for model, want := range map[string]string{
+		"Model: \t\t NVIDIA A100-SXM4-40GB\n":   "a100",
+		"Model: \t\t NVIDIA GeForce RTX 4090\n": "rtx_4090",
+		"Model: \t\t Tesla T4\n":                "t4",
+		"IRQ: \t\t 180\n":                       "",
+	} {
+		if got := parseGPUModel(model); got != want {
+			t.Fatalf("%q: expected %q, got %q", model, want, got)
+		}
+	}
+}
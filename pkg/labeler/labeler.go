@@ -16,6 +16,9 @@ type Labels struct {
 	CoresPerSocket  int
 	ThreadsPerCore  int
 	RealMemory      int
+
+	GPUs    int
+	GPUType string
 }
 
 // NewLabeler returns a labeler based on slurmd -C output
@@ -0,0 +1,118 @@
+// Package power receives slurm ResumeProgram and SuspendProgram calls for elastic nodes via http
+package power
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vultr/slik/pkg/connectors"
+	"github.com/vultr/slik/pkg/slurm"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// PowerAPI configuration for the http power api
+type PowerAPI struct {
+	Listen string
+	Port   uint16
+
+	app *fiber.App
+}
+
+// NewPowerAPI creates a new power api server
+func NewPowerAPI(name, listen string, port uint16) (*PowerAPI, error) {
+	var p PowerAPI
+
+	// Initialize engine
+	app := fiber.New(fiber.Config{
+		AppName:               name,
+		EnablePrintRoutes:     false,
+		Prefork:               false,
+		Concurrency:           50,
+		ServerHeader:          name,
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          30 * time.Second,
+		IdleTimeout:           30 * time.Second,
+		DisableKeepalive:      true,
+		DisableStartupMessage: true,
+	})
+
+	// body is the comma separated node list
+	app.Post("/v1/:namespace/:name/resume", PostResume)
+	app.Post("/v1/:namespace/:name/suspend", PostSuspend)
+
+	p.app = app
+	p.Listen = listen
+	p.Port = port
+
+	return &p, nil
+}
+
+// Start starts the server
+func (p *PowerAPI) Start() error {
+	return p.app.Listen(fmt.Sprintf("%s:%d", p.Listen, p.Port))
+}
+
+// Shutdown shuts down the server
+func (p *PowerAPI) Shutdown() error {
+	return p.app.Shutdown()
+}
+
+// PostResume responds to ResumeProgram calls
+func PostResume(c *fiber.Ctx) error {
+	return setPower(c, true)
+}
+
+// PostSuspend responds to SuspendProgram calls
+func PostSuspend(c *fiber.Ctx) error {
+	return setPower(c, false)
+}
+
+func setPower(c *fiber.Ctx, resume bool) error {
+	log := zap.L().Sugar()
+
+	namespace := c.Params("namespace")
+	name := c.Params("name")
+
+	client, err := connectors.GetKubernetesConn()
+	if err != nil {
+		return err
+	}
+
+	token, _ := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !slurm.CheckPowerToken(client, namespace, name, token) {
+		log.With(
+			"context", "power",
+			"source", c.IP(),
+			"url", c.OriginalURL(),
+		).Warn("invalid power token")
+
+		c.Status(fiber.StatusUnauthorized)
+
+		return c.JSON(fiber.Map{
+			"message": "unauthorized",
+		})
+	}
+
+	nodes := strings.Split(strings.TrimSpace(string(c.Body())), ",")
+	if err := slurm.SetElasticPower(client, namespace, name, nodes, resume); err != nil {
+		if errors.Is(err, slurm.ErrNotElasticNode) {
+			c.Status(fiber.StatusBadRequest)
+
+			return c.JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+
+		return err
+	}
+
+	c.Status(fiber.StatusOK)
+
+	return c.JSON(fiber.Map{
+		"message": "done",
+	})
+}
@@ -0,0 +1,31 @@
+package reconciler
+
+import (
+	"github.com/vultr/slik/cmd/slik/metrics"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurm"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// updateAutoscaling publishes the pending slurm demand as metrics and scales the balloon pods to it, without
+// autoscaling squeue is not run and the balloon pods are removed
+func updateAutoscaling(cs kubernetes.Interface, exec slurm.Executor, s *v1s.Slik) error {
+	if !s.Spec.Autoscaling.Enabled {
+		metrics.ResetSlurmDemand(s.Name)
+
+		return slurm.BuildBalloonDeployment(cs, s, nil)
+	}
+
+	demands, err := slurm.PendingDemand(cs, exec, s)
+	if err != nil {
+		return err
+	}
+
+	metrics.ResetSlurmDemand(s.Name)
+	for _, d := range demands {
+		metrics.SetSlurmDemand(s.Name, d.Partition, d.Jobs, d.Nodes, d.CPUs, d.MemoryMB)
+	}
+
+	return slurm.BuildBalloonDeployment(cs, s, demands)
+}
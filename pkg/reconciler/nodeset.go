@@ -0,0 +1,68 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+
+	client "github.com/vultr/slik/pkg/clientset/v1"
+	"github.com/vultr/slik/pkg/connectors"
+	"github.com/vultr/slik/pkg/slurm"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileNodeSets runs the slurmd pods of every SlurmNodeSet, except those of paused clusters keyed by
+// namespace/name
+func reconcileNodeSets(slurmcs *client.V1Client, paused map[string]bool) error {
+	log := zap.L().Sugar()
+
+	nodeSets, err := slurmcs.SlurmNodeSet(context.TODO(), "").List(v1.ListOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if len(nodeSets.Items) == 0 {
+		return nil
+	}
+
+	cs, err := connectors.GetKubernetesConn()
+	if err != nil {
+		return err
+	}
+
+	kubeConfig, err := connectors.GetKubernetesConfig()
+	if err != nil {
+		return err
+	}
+
+	exec := slurm.NewPodExecutor(cs, kubeConfig)
+
+	for i := range nodeSets.Items {
+		ns := nodeSets.Items[i]
+
+		if ns.DeletionTimestamp != nil || paused[ns.Namespace+"/"+ns.Spec.Cluster] {
+			continue
+		}
+
+		log.Infof("on slurm nodeset: %s/%s", ns.Namespace, ns.Name)
+
+		status := ns.Status.DeepCopy()
+		if err := slurm.ReconcileNodeSet(cs, exec, &ns); err != nil {
+			log.Error(err)
+		}
+
+		if reflect.DeepEqual(*status, ns.Status) {
+			continue
+		}
+
+		if _, err := slurmcs.SlurmNodeSet(context.TODO(), ns.Namespace).UpdateStatus(&ns, v1.UpdateOptions{}); err != nil {
+			log.Error(err)
+		}
+	}
+
+	return nil
+}
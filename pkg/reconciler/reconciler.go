@@ -2,16 +2,20 @@ package reconciler
 
 import (
 	"context"
+	"reflect"
 	"time"
 
+	"github.com/vultr/slik/cmd/slik/metrics"
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	client "github.com/vultr/slik/pkg/clientset/v1"
 	"github.com/vultr/slik/pkg/connectors"
 	"github.com/vultr/slik/pkg/slurm"
 
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Reconciler type
@@ -72,6 +76,8 @@ func loop() error {
 		}
 	}
 
+	paused := map[string]bool{}
+
 	for i := range sliks.Items {
 		log.Infof("on slik cluster: %s, %+v",
 			sliks.Items[i].Name,
@@ -90,12 +96,20 @@ func loop() error {
 				continue
 			}
 
+			if err := slurm.DeleteSlurmdNodeSet(slurmcs.SlurmNodeSet(context.TODO(), s.Namespace), s.Name); err != nil {
+				log.Error(err)
+
+				continue
+			}
+
 			if err := slurm.SlurmDelete(cs, s.Name, s.Spec.Namespace); err != nil {
 				log.Error(err)
 
 				continue
 			}
 
+			metrics.ResetSlurmDemand(s.Name)
+
 			s.ObjectMeta.Finalizers = []string{}
 
 			if _, err := slurmcs.Slik(context.TODO()).Update(&s, v1.UpdateOptions{}); err != nil {
@@ -107,6 +121,26 @@ func loop() error {
 			continue // item is deleted, next pls
 		}
 
+		if s.Spec.Paused != s.Status.Paused {
+			s.Status.Paused = s.Spec.Paused
+			s2, err := slurmcs.Slik(context.TODO()).UpdateStatus(&s, v1.UpdateOptions{})
+			if err != nil {
+				log.Error(err)
+
+				continue
+			}
+
+			s = *s2
+		}
+
+		if s.Spec.Paused {
+			log.Infof("slurm cluster %s is paused, skipping", s.Name)
+
+			paused[s.Namespace+"/"+s.Name] = true
+
+			continue
+		}
+
 		switch s.Status.State {
 		case "":
 			log.Infof("slurm cluster initializing: %s", s.Name)
@@ -149,12 +183,26 @@ func loop() error {
 				continue
 			}
 
+			if slurm.DryRun(&s) {
+				if err := dryRun(cs, slurmcs, &s); err != nil {
+					log.Error(err)
+				}
+
+				continue
+			}
+
 			if err := slurm.CreateSlurm(cs, &s); err != nil {
 				log.Error(err)
 
 				continue
 			}
 
+			if err := slurm.BuildSlurmdNodeSet(cs, slurmcs.SlurmNodeSet(context.TODO(), s.Namespace), &s); err != nil {
+				log.Error(err)
+
+				continue
+			}
+
 			s.Status.State = StateActive
 			if _, err := slurmcs.Slik(context.TODO()).UpdateStatus(&s, v1.UpdateOptions{}); err != nil {
 				log.Error(err)
@@ -179,12 +227,28 @@ func loop() error {
 				continue
 			}
 
-			if err := slurm.CreateSlurm(cs, &s); err != nil {
+			kubeConfig, err := connectors.GetKubernetesConfig()
+			if err != nil {
 				log.Error(err)
 
 				continue
 			}
 
+			// status keeps drain and reconfigure progress, it is saved even if a step fails
+			status := s.Status.DeepCopy()
+
+			if err := updateSlurm(cs, slurm.NewPodExecutor(cs, kubeConfig), slurmcs, &s); err != nil {
+				log.Error(err)
+			}
+
+			if !reflect.DeepEqual(*status, s.Status) {
+				if _, err := slurmcs.Slik(context.TODO()).UpdateStatus(&s, v1.UpdateOptions{}); err != nil {
+					log.Error(err)
+
+					continue
+				}
+			}
+
 		case StateFailed:
 			log.Infof("checking failed slurm cluster: %s", s.Name)
 
@@ -203,26 +267,104 @@ func loop() error {
 		}
 	}
 
+	return reconcileNodeSets(slurmcs, paused)
+}
+
+// dryRun saves the changes a reconcile would make to status.pendingChanges instead of applying them
+func dryRun(cs kubernetes.Interface, slurmcs *client.V1Client, s *v1s.Slik) error {
+	pending := s.Status.PendingChanges
+
+	if err := pendingChanges(cs, slurmcs, s); err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(pending, s.Status.PendingChanges) {
+		return nil
+	}
+
+	_, err := slurmcs.Slik(context.TODO()).UpdateStatus(s, v1.UpdateOptions{})
+
+	return err
+}
+
+// pendingChanges sets status.pendingChanges to the changes a reconcile would make
+func pendingChanges(cs kubernetes.Interface, slurmcs *client.V1Client, s *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	changes, err := slurm.PendingChanges(cs, slurmcs.SlurmNodeSet(context.TODO(), s.Namespace), s)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("slurm cluster %s dry-run, %d pending changes", s.Name, len(changes))
+
+	// an empty list does not replace a nil one, the status is only saved when it changed
+	if !equality.Semantic.DeepEqual(changes, s.Status.PendingChanges) {
+		s.Status.PendingChanges = changes
+	}
+
 	return nil
 }
 
-// checks returns true if all checks pass
-func checks(s *v1s.Slik) bool {
+// updateSlurm applies the spec of an active cluster
+func updateSlurm(cs kubernetes.Interface, exec slurm.Executor, slurmcs *client.V1Client, s *v1s.Slik) error {
 	log := zap.L().Sugar()
 
-	// these checks only matter if a db is in use
-	if s.Spec.Slurmdbd {
-		q, err := resource.ParseQuantity(s.Spec.MariaDB.StorageSize)
-		if err != nil {
-			log.Error(err)
-			log.Warnf("mariadb.storage_size %s is not valid, setting cluster to failed state", s.Spec.MariaDB.StorageSize)
+	// drained nodes have to leave slurm before slurm.conf is rendered without them
+	if err := slurm.DrainNodes(cs, exec, s); err != nil {
+		return err
+	}
 
-			return false
-		}
+	// slurmctld may be down until a fixed spec is applied, maintenance is retried on the next reconcile
+	if err := slurm.Maintenance(cs, exec, s); err != nil {
+		log.Errorf("slurm cluster %s maintenance: %s", s.Name, err)
+	}
 
-		q2, _ := resource.ParseQuantity("45G")
-		if q.Cmp(q2) == -1 {
-			log.Warnf("mariadb.storage_size must be at least 45G")
+	// with dry-run, the changes to the spec are only listed while nodes keep being drained and reconfigured
+	if err := applySpec(cs, slurmcs, s); err != nil {
+		return err
+	}
+
+	if err := slurm.ReconcileElasticNodes(cs, s); err != nil {
+		return err
+	}
+
+	if err := slurm.UpdateSlurmdDisruptionBudgets(cs, exec, s); err != nil {
+		return err
+	}
+
+	// the demand is published again on the next reconcile, slurm.conf changes are not held back by it
+	if err := updateAutoscaling(cs, exec, s); err != nil {
+		log.Errorf("slurm cluster %s autoscaling: %s", s.Name, err)
+	}
+
+	// node and partition changes are applied without restarting the pods
+	return slurm.Reconfigure(cs, exec, s)
+}
+
+// applySpec creates or updates the objects of the cluster, or lists them in status.pendingChanges with dry-run
+func applySpec(cs kubernetes.Interface, slurmcs *client.V1Client, s *v1s.Slik) error {
+	if slurm.DryRun(s) {
+		return pendingChanges(cs, slurmcs, s)
+	}
+
+	// the spec is applied, the changes of a previous dry-run are no longer pending
+	s.Status.PendingChanges = nil
+
+	if err := slurm.CreateSlurm(cs, s); err != nil {
+		return err
+	}
+
+	return slurm.BuildSlurmdNodeSet(cs, slurmcs.SlurmNodeSet(context.TODO(), s.Namespace), s)
+}
+
+// checks returns true if all checks pass, the admission webhook rejects the same rules before they are applied
+func checks(s *v1s.Slik) bool {
+	log := zap.L().Sugar()
+
+	for _, err := range []error{slurm.ValidateStorage(s), slurm.ValidateSpec(s)} {
+		if err != nil {
+			log.Warnf("slik %s is not valid, setting cluster to failed state: %s", s.Name, err)
 
 			return false
 		}
@@ -0,0 +1,204 @@
+package slurm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// demandReasons pending reasons that more nodes would resolve, held or dependent jobs are not demand
+var demandReasons = []string{"Resources", "Priority"}
+
+// Demand unmet demand of the pending jobs in a partition
+type Demand struct {
+	Partition string
+	Jobs      int
+	Nodes     int
+	CPUs      int
+	MemoryMB  int
+}
+
+// PendingDemand returns the unmet demand per partition, sorted by partition
+func PendingDemand(client kubernetes.Interface, exec Executor, wl *v1s.Slik) ([]Demand, error) {
+	out, err := slurmCommand(client, exec, wl,
+		"squeue", "--noheader", "--states=PENDING", "--format=%P|%D|%C|%m|%r")
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePendingJobs(out), nil
+}
+
+// parsePendingJobs sums squeue lines of partition|nodes|cpus|min memory per node|reason
+func parsePendingJobs(out string) []Demand {
+	demands := map[string]*Demand{}
+	for line := range strings.Lines(out) {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 5 || !slices.Contains(demandReasons, fields[4]) {
+			continue
+		}
+
+		nodes, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		cpus, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		// jobs submitted to several partitions are counted in the first
+		partition, _, _ := strings.Cut(fields[0], ",")
+
+		d, ok := demands[partition]
+		if !ok {
+			d = &Demand{Partition: partition}
+			demands[partition] = d
+		}
+
+		d.Jobs++
+		d.Nodes += nodes
+		d.CPUs += cpus
+		d.MemoryMB += parseMemoryMB(fields[3]) * nodes
+	}
+
+	result := []Demand{}
+	for _, partition := range slices.Sorted(maps.Keys(demands)) {
+		result = append(result, *demands[partition])
+	}
+
+	return result
+}
+
+// memoryUnitsMB squeue memory suffixes in MB
+var memoryUnitsMB = map[string]float64{"K": 1.0 / 1024, "M": 1, "G": 1024, "T": 1024 * 1024}
+
+// parseMemoryMB parses squeue memory like 500M or 4G, unknown values count as 0
+func parseMemoryMB(s string) int {
+	multiplier := 1.0
+	if s != "" {
+		if unit, ok := memoryUnitsMB[s[len(s)-1:]]; ok {
+			multiplier = unit
+			s = s[:len(s)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+
+	return int(value * multiplier)
+}
+
+// balloonReplicas returns the nodes needed for the demand, capped at autoscaling.maxNodes
+func balloonReplicas(wl *v1s.Slik, demands []Demand) int32 {
+	as := &wl.Spec.Autoscaling
+
+	var nodes, cpus, memory int
+	for i := range demands {
+		nodes += demands[i].Nodes
+		cpus += demands[i].CPUs
+		memory += demands[i].MemoryMB
+	}
+
+	needed := nodes
+	if as.NodeCPUs > 0 {
+		needed = max(needed, ceilDiv(cpus, int(as.NodeCPUs)))
+	}
+
+	if as.NodeRealMemory > 0 {
+		needed = max(needed, ceilDiv(memory, int(as.NodeRealMemory)))
+	}
+
+	return int32(min(needed, int(as.MaxNodes)))
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// BuildBalloonDeployment scales the balloon pods to the pending demand, they are removed when autoscaling is disabled
+func BuildBalloonDeployment(client kubernetes.Interface, wl *v1s.Slik, demands []Demand) error {
+	log := zap.L().Sugar()
+
+	name := fmt.Sprintf("%s-balloon", wl.Name)
+	if !wl.Spec.Autoscaling.Enabled {
+		return DeploymentDelete(client, name, wl.Namespace)
+	}
+
+	replicas := balloonReplicas(wl, demands)
+	var grace int64 = 0
+
+	balloonDep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          name,
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": name,
+				},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":                          name,
+						"app.kubernetes.io/managed-by": "slik",
+					},
+				},
+				Spec: v1.PodSpec{
+					// preempted by any other pod, the autoscaler still adds nodes for them
+					PriorityClassName:             BalloonPriorityClass,
+					TerminationGracePeriodSeconds: &grace,
+					NodeSelector:                  wl.Spec.Autoscaling.NodeSelector,
+					Affinity: &v1.Affinity{
+						PodAntiAffinity: &v1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+								{
+									LabelSelector: &metav1.LabelSelector{
+										MatchLabels: map[string]string{
+											"app": name,
+										},
+									},
+									TopologyKey: "kubernetes.io/hostname",
+								},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "balloon",
+							Image: config.GetSlurmBalloonImage(),
+							Resources: v1.ResourceRequirements{
+								Requests: wl.Spec.Autoscaling.BalloonResources,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	log.Infof("balloon deployment: %+v", balloonDep)
+
+	return applyDeployment(client, balloonDep)
+}
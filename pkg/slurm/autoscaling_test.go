@@ -0,0 +1,96 @@
+package slurm
+
+import (
+	"reflect"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParsePendingJobs(t *testing.T) {
+	out := "batch|2|8|4G|Resources\n" +
+		"batch|1|4|500M|Priority\n" +
+		"gpu,batch|1|16|0|Resources\n" +
+		"batch|1|1|1G|Dependency\n" +
+		"batch|1|1|1G|JobHeldUser\n"
+
+	want := []Demand{
+		{Partition: "batch", Jobs: 2, Nodes: 3, CPUs: 12, MemoryMB: 8692},
+		{Partition: "gpu", Jobs: 1, Nodes: 1, CPUs: 16, MemoryMB: 0},
+	}
+
+	if got := parsePendingJobs(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestBuildBalloonDeployment(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-slurmctld-abc",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-slurmctld"},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	})
+	exec := &fakeExecutor{outputs: map[string]string{"squeue": "batch|1|40|8G|Resources\nbatch|1|2|64G|Resources\n"}}
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Autoscaling: v1s.Autoscaling{
+				Enabled:        true,
+				MaxNodes:       4,
+				NodeCPUs:       16,
+				NodeRealMemory: 32000,
+				BalloonResources: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("15"),
+				},
+			},
+		},
+	}
+
+	demands, err := PendingDemand(client, exec, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := BuildBalloonDeployment(client, wl, demands); err != nil {
+		t.Fatal(err)
+	}
+
+	dep, err := GetDeployment(client, "test-balloon", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 42 cpus need 3 nodes, 72G of memory needs 3 nodes as well
+	if *dep.Spec.Replicas != 3 || dep.Spec.Template.Spec.PriorityClassName != BalloonPriorityClass {
+		t.Fatalf("unexpected balloon deployment: %+v", dep.Spec)
+	}
+
+	// capped at maxNodes
+	exec.outputs["squeue"] = "batch|10|160|0|Resources\n"
+	demands, err = PendingDemand(client, exec, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replicas := balloonReplicas(wl, demands); replicas != 4 {
+		t.Fatalf("expected 4 balloons, got %d", replicas)
+	}
+
+	wl.Spec.Autoscaling.Enabled = false
+	if err := BuildBalloonDeployment(client, wl, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if DeploymentExists(client, "test-balloon", "default") {
+		t.Fatal("expected balloon deployment to be removed")
+	}
+}
@@ -0,0 +1,37 @@
+package slurm
+
+import (
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// withCgroups mounts the cgroup hierarchy of the node, slurmd creates the job cgroups there
+func withCgroups(tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) {
+	if !wl.Spec.Cgroups.Enabled {
+		return
+	}
+
+	hostPathType := v1.HostPathDirectory
+
+	tpl.Spec.Volumes = append(tpl.Spec.Volumes, v1.Volume{
+		Name: "cgroup",
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{
+				Path: CgroupMount,
+				Type: &hostPathType,
+			},
+		},
+	})
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].VolumeMounts = append(tpl.Spec.Containers[i].VolumeMounts, v1.VolumeMount{
+			Name:      "cgroup",
+			MountPath: CgroupMount,
+		})
+	}
+}
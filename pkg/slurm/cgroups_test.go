@@ -0,0 +1,71 @@
+package slurm
+
+import (
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCgroups(t *testing.T) {
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false))
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Cgroups: v1s.Cgroups{Enabled: true, ConstrainCores: true, ConstrainRAMSpace: true},
+		},
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"\nProctrackType=proctrack/cgroup\n", "\nTaskPlugin=task/cgroup,task/affinity\n"} {
+		if !strings.Contains(cm.Data["slurm.conf"], line) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", line, cm.Data["slurm.conf"])
+		}
+	}
+
+	for _, line := range []string{"ConstrainCores=yes\n", "ConstrainRAMSpace=yes\n", "ConstrainDevices=no\n"} {
+		if !strings.Contains(cm.Data["cgroup.conf"], line) {
+			t.Fatalf("expected %q in cgroup.conf:\n%s", line, cm.Data["cgroup.conf"])
+		}
+	}
+
+	tpl, err := mkSlurmdPodTemplate(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mounted := false
+	for _, mount := range tpl.Spec.Containers[0].VolumeMounts {
+		mounted = mounted || (mount.Name == "cgroup" && mount.MountPath == CgroupMount && !mount.ReadOnly)
+	}
+
+	if !mounted {
+		t.Fatalf("expected the cgroup hierarchy mounted into slurmd: %+v", tpl.Spec.Containers[0].VolumeMounts)
+	}
+
+	// disabled by default
+	wl.Spec.Cgroups = v1s.Cgroups{}
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err = GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cm.Data["cgroup.conf"]; ok || !strings.Contains(cm.Data["slurm.conf"], "\nProctrackType=proctrack/linuxproc\nTaskPlugin=task/none\n") {
+		t.Fatalf("expected linuxproc without cgroups: %+v", cm.Data)
+	}
+}
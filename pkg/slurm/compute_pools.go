@@ -0,0 +1,148 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// computePoolsEnabled returns true if slurmd runs in compute pools instead of one pod per kubernetes node
+func computePoolsEnabled(wl *v1s.Slik) bool {
+	return len(wl.Spec.ComputePools) > 0
+}
+
+// computePoolName is the StatefulSet of a pool, its pods and slurm nodes are <name>-<pool>-<ordinal>
+func computePoolName(wl *v1s.Slik, pool *v1s.ComputePool) string {
+	return fmt.Sprintf("%s-%s", wl.Name, pool.Name)
+}
+
+// computePoolShape returns the slurm CPUs and RealMemory (MB) of a pool from the slurmd limits
+func computePoolShape(pool *v1s.ComputePool) (int, int) {
+	cpus := int(pool.Resources.Limits.Cpu().MilliValue() / 1000)
+	memory := int(pool.Resources.Limits.Memory().Value() / (1024 * 1024))
+
+	return max(cpus, 1), memory
+}
+
+// computePoolNodes returns the slurm nodes and a partition per pool, named without the cluster prefix like static nodes
+func computePoolNodes(wl *v1s.Slik) ([]slurmconf.Node, []slurmconf.Partition) {
+	nodes := []slurmconf.Node{}
+	partitions := []slurmconf.Partition{}
+
+	for i := range wl.Spec.ComputePools {
+		pool := &wl.Spec.ComputePools[i]
+		cpus, memory := computePoolShape(pool)
+
+		partition := slurmconf.Partition{Name: pool.Name}
+		for j := 0; j < int(pool.Replicas); j++ {
+			node := fmt.Sprintf("%s-%d", pool.Name, j)
+
+			nodes = append(nodes, slurmconf.Node{
+				NodeName:       node,
+				CPUs:           cpus,
+				ThreadsPerCore: 1,
+				RealMemory:     memory,
+			})
+			partition.Nodes = append(partition.Nodes, fmt.Sprintf("%s-%s", wl.Name, node))
+		}
+
+		if len(partition.Nodes) > 0 {
+			partitions = append(partitions, partition)
+		}
+	}
+
+	return nodes, partitions
+}
+
+// buildComputePools runs a StatefulSet per compute pool and removes the ones no longer in the spec
+func buildComputePools(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	wanted := map[string]bool{}
+
+	if computePoolsEnabled(wl) {
+		tpl, err := mkSlurmdPodTemplate(client, wl)
+		if err != nil {
+			return err
+		}
+
+		for i := range wl.Spec.ComputePools {
+			pool := &wl.Spec.ComputePools[i]
+			sts := mkComputePoolStatefulSet(tpl, wl, pool)
+			wanted[sts.Name] = true
+
+			log.Infof("compute pool statefulset: %+v", sts)
+
+			if err := applyStatefulSet(client, sts); err != nil {
+				return err
+			}
+		}
+	}
+
+	existing, err := client.AppsV1().StatefulSets(wl.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: ComputePoolLabel,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range existing.Items {
+		if existing.Items[i].Labels["app"] != fmt.Sprintf("%s-slurmd", wl.Name) || wanted[existing.Items[i].Name] {
+			continue
+		}
+
+		if err := StatefulSetDelete(client, existing.Items[i].Name, wl.Namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mkComputePoolStatefulSet(tpl *v1.PodTemplateSpec, wl *v1s.Slik, pool *v1s.ComputePool) *appsv1.StatefulSet {
+	tpl = tpl.DeepCopy()
+	name := computePoolName(wl, pool)
+	replicas := pool.Replicas
+
+	tpl.Labels[ComputePoolLabel] = pool.Name
+	tpl.Spec.NodeSelector = pool.NodeSelector
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name == "slurmd" {
+			tpl.Spec.Containers[i].Resources = pool.Resources
+		}
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+				ComputePoolLabel:               pool.Name,
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			// pods resolve as <pod>.<name>-slurmd, the NodeAddr in slurm.conf
+			ServiceName:         fmt.Sprintf("%s-slurmd", wl.Name),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":            fmt.Sprintf("%s-slurmd", wl.Name),
+					ComputePoolLabel: pool.Name,
+				},
+			},
+			Template: *tpl,
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package slurm
+
+import (
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func computePoolCluster() *v1s.Slik {
+	return &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			ComputePools: []v1s.ComputePool{
+				{
+					Name:     "small",
+					Replicas: 2,
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("4"),
+							v1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+					NodeSelector: map[string]string{"pool": "shared"},
+				},
+			},
+		},
+	}
+}
+
+func TestComputePoolSlurmConf(t *testing.T) {
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false))
+
+	if err := buildSlurmconfConfigMap(client, computePoolCluster()); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := cm.Data["slurm.conf"]
+	for _, want := range []string{
+		"NodeName=test-small-1 NodeAddr=test-small-1.test-slurmd CPUs=4 RealMemory=8192 ThreadsPerCore=1",
+		"PartitionName=small Nodes=test-small-0,test-small-1 MaxTime=60 State=UP",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", want, conf)
+		}
+	}
+
+	if strings.Contains(conf, "NodeName=test-a ") {
+		t.Fatalf("expected slurmable kubernetes nodes not to be used with compute pools:\n%s", conf)
+	}
+}
+
+func TestBuildComputePools(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	wl := computePoolCluster()
+
+	if err := buildComputePools(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	sts, err := GetStatefulSet(client, "test-small", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *sts.Spec.Replicas != 2 || sts.Spec.ServiceName != "test-slurmd" ||
+		sts.Spec.Template.Spec.NodeSelector["pool"] != "shared" {
+		t.Fatalf("unexpected compute pool statefulset: %+v", sts.Spec)
+	}
+
+	for _, c := range sts.Spec.Template.Spec.Containers {
+		if c.Name == "slurmd" && !c.Resources.Limits.Cpu().Equal(resource.MustParse("4")) {
+			t.Fatalf("expected pool limits on slurmd, got %+v", c.Resources)
+		}
+	}
+
+	// removed from the spec, the statefulset is deleted
+	wl.Spec.ComputePools = nil
+	if err := buildComputePools(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GetStatefulSet(client, "test-small", "default"); err == nil {
+		t.Fatal("expected compute pool statefulset to be removed")
+	}
+}
@@ -0,0 +1,83 @@
+package slurm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// confServer returns the --conf-server value, every slurmctld is listed in HA mode
+func confServer(wl *v1s.Slik) string {
+	if !wl.Spec.Slurmctld.HighAvailability {
+		return fmt.Sprintf("%s-slurmctld", wl.Name)
+	}
+
+	hosts := []string{}
+	for i := 0; i < int(SlurmctldHAReplicas); i++ {
+		hosts = append(hosts, fmt.Sprintf("%s-slurmctld-%d.%s-slurmctld-hosts", wl.Name, i, wl.Name))
+	}
+
+	return strings.Join(hosts, ",")
+}
+
+// withConfServer starts slurmd in the named container with --conf-server instead of the slurm ConfigMap
+func withConfServer(tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) {
+	if !wl.Spec.Configless {
+		return
+	}
+
+	dropSlurmConf(tpl, wl)
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		// read by the slurmd entrypoint
+		tpl.Spec.Containers[i].Env = append(tpl.Spec.Containers[i].Env, v1.EnvVar{
+			Name:  "X_VULTR_SLURM_CONF_SERVER",
+			Value: confServer(wl),
+		})
+	}
+}
+
+// withClientConfServer replaces the slurm ConfigMap of a client pod with SLURM_CONF_SERVER, the Slurm
+// commands of the named container then fetch slurm.conf from slurmctld
+func withClientConfServer(tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) {
+	if !wl.Spec.Configless {
+		return
+	}
+
+	dropSlurmConf(tpl, wl)
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].Env = append(tpl.Spec.Containers[i].Env, v1.EnvVar{
+			Name:  "SLURM_CONF_SERVER",
+			Value: confServer(wl),
+		})
+	}
+}
+
+// dropSlurmConf removes the slurm ConfigMap volume, its mounts and its checksum annotation
+func dropSlurmConf(tpl *v1.PodTemplateSpec, wl *v1s.Slik) {
+	tpl.Spec.Volumes = slices.DeleteFunc(tpl.Spec.Volumes, func(v v1.Volume) bool {
+		return v.Name == "slurm"
+	})
+
+	for i := range tpl.Spec.Containers {
+		tpl.Spec.Containers[i].VolumeMounts = slices.DeleteFunc(tpl.Spec.Containers[i].VolumeMounts, func(m v1.VolumeMount) bool {
+			return m.Name == "slurm"
+		})
+	}
+
+	// slurm.conf changes reach the pod through scontrol reconfigure
+	delete(tpl.Annotations, fmt.Sprintf("slik.vultr.com/checksum-%s-slurm", wl.Name))
+}
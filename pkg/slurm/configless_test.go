@@ -0,0 +1,128 @@
+package slurm
+
+import (
+	"context"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfiglessSlurmd(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-slurm", Namespace: "default"},
+		Data:       map[string]string{"slurm.conf": "ClusterName=cluster\n"},
+	})
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Configless: true},
+	}
+
+	tpl, err := mkSlurmdPodTemplate(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, volume := range tpl.Spec.Volumes {
+		if volume.Name == "slurm" {
+			t.Fatal("expected no slurm configmap volume")
+		}
+	}
+
+	if _, ok := tpl.Annotations["slik.vultr.com/checksum-test-slurm"]; ok {
+		t.Fatal("expected no slurm.conf checksum annotation")
+	}
+
+	found := false
+	for _, env := range tpl.Spec.Containers[0].Env {
+		if env.Name == "X_VULTR_SLURM_CONF_SERVER" && env.Value == "test-slurmctld" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected conf server env, got %+v", tpl.Spec.Containers[0].Env)
+	}
+}
+
+func TestWithClientConfServer(t *testing.T) {
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Configless: true,
+			Slurmctld:  v1s.Slurmctld{HighAvailability: true},
+		},
+	}
+
+	tpl := &v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"slik.vultr.com/checksum-test-slurm": "abc"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:         "login",
+					Image:        "login:1",
+					VolumeMounts: []v1.VolumeMount{{Name: "slurm", MountPath: "/etc/slurm"}},
+				},
+			},
+			Volumes: []v1.Volume{{Name: "slurm"}},
+		},
+	}
+
+	withClientConfServer(tpl, wl, "login")
+
+	// the images ship Slurm 21.08, without sackd
+	if len(tpl.Spec.InitContainers) != 0 {
+		t.Fatalf("expected no sidecar, got %+v", tpl.Spec.InitContainers)
+	}
+
+	if len(tpl.Spec.Volumes) != 0 || len(tpl.Spec.Containers[0].VolumeMounts) != 0 || len(tpl.Annotations) != 0 {
+		t.Fatalf("expected the slurm configmap to be dropped, got %+v", tpl)
+	}
+
+	env := tpl.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "SLURM_CONF_SERVER" ||
+		env[0].Value != "test-slurmctld-0.test-slurmctld-hosts,test-slurmctld-1.test-slurmctld-hosts" {
+		t.Fatalf("unexpected env: %+v", env)
+	}
+}
+
+func TestConfiglessSlurmrestd(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Configless: true, Slurmdbd: true, Slurmrestd: true},
+	}
+
+	if err := buildSlurmrestdDeployment(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	dep, err := client.AppsV1().Deployments("default").Get(context.TODO(), "test-slurmrestd", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, volume := range dep.Spec.Template.Spec.Volumes {
+		if volume.Name == "slurm" {
+			t.Fatal("expected no slurm configmap volume")
+		}
+	}
+
+	found := false
+	for _, env := range dep.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "SLURM_CONF_SERVER" && env.Value == "test-slurmctld" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected conf server env, got %+v", dep.Spec.Template.Spec.Containers[0].Env)
+	}
+}
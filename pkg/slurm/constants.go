@@ -1,5 +1,7 @@
 package slurm
 
+import "regexp"
+
 const (
 	WorkloadStatusPending   string = "Pending"
 	WorkloadStatusRunning   string = "Running"
@@ -12,3 +14,90 @@ const (
 	ConflictRetryIntervalSec int64 = 1
 	SlurmablerWaitTimeoutSec int   = 300
 )
+
+const (
+	SlurmctldHAReplicas       int32  = 2
+	SlurmctldStateStorageSize string = "1Gi"
+	MariaDBMinStorageSize     string = "45G"
+)
+
+const (
+	// MaxStatefulSetNameLength leaves room for the -<hash> suffix of the controller-revision-hash pod label
+	MaxStatefulSetNameLength int = 52
+)
+
+const (
+	// DrainAnnotation requests a drain of the slurm node on a kubernetes node, the value is used as reason
+	DrainAnnotation    string = "slik.vultr.com/drain"
+	DrainTimeoutSec    int32  = 3600
+	DrainDefaultReason string = "kubernetes node cordoned"
+)
+
+const (
+	// MaintenanceReason of the slurm nodes drained by spec.maintenance, only those are resumed after it
+	MaintenanceReason string = "slik maintenance"
+)
+
+const (
+	// DryRunAnnotation set to "true" on a Slik computes status.pendingChanges instead of applying the spec
+	DryRunAnnotation string = "slik.vultr.com/dry-run"
+)
+
+const (
+	NodeSetLabel              string = "slik.vultr.com/nodeset"
+	NodeSetRevisionAnnotation string = "slik.vultr.com/revision"
+	NodeSetUpdateReason       string = "slik rolling update"
+)
+
+const (
+	// ElasticNodeLabel marks kubernetes nodes running elastic slurmd pods, the value is the cluster name
+	ElasticNodeLabel        string = "slik.vultr.com/elastic"
+	ElasticPowerTokenLength uint   = 32
+	ElasticPowerSecretMount string = "/etc/slik/power"
+	// ElasticRevisionAnnotation hash of the slurmd pod template an elastic pod was created from
+	ElasticRevisionAnnotation string = "slik.vultr.com/revision"
+)
+
+const (
+	// BalloonPriorityClass negative priority class of balloon pods, installed by the helm chart
+	BalloonPriorityClass string = "slik-balloon"
+)
+
+const (
+	// ComputePoolLabel marks the StatefulSets and pods of a compute pool, the value is the pool name
+	ComputePoolLabel string = "slik.vultr.com/pool"
+)
+
+const (
+	// GPUResource is the extended resource of the NVIDIA device plugin, requested by slurmd for the GPUs of its node
+	GPUResource string = "nvidia.com/gpu"
+)
+
+const (
+	// CgroupMount is the cgroup hierarchy of the node, mounted into slurmd with spec.cgroups
+	CgroupMount string = "/sys/fs/cgroup"
+	// CgroupsMinSlurmVersion is the first release with the cgroup/v2 plugin and IgnoreSystemd of cgroup.conf
+	CgroupsMinSlurmVersion string = "23.02"
+	// LiveNodesMinSlurmVersion is the first release adding and removing NodeName lines on scontrol reconfigure,
+	// earlier releases restart the daemons
+	LiveNodesMinSlurmVersion string = "23.11"
+)
+
+const (
+	// ScriptsMount is where spec.scripts are mounted into slurmd and slurmctld
+	ScriptsMount string = "/etc/slik/scripts"
+)
+
+// ReservedMountPaths are mounted by slik and can not be used by shared volumes
+var ReservedMountPaths = []string{
+	"/etc/munge",
+	"/etc/slurm",
+	"/run/munge",
+	"/var/lib/slurm/slurmctld",
+}
+
+// ReservedComputePoolNames are partitions slik renders itself
+var ReservedComputePoolNames = []string{"batch", "elastic"}
+
+// LoginUserRegexp matches the user names accepted for login authorized keys
+var LoginUserRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
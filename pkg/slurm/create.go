@@ -9,6 +9,10 @@ import (
 
 // CreateSlurm launches a slurm cluster on the k8s cluster
 func CreateSlurm(client kubernetes.Interface, wl *v1s.Slik) error {
+	if err := validateSharedVolumes(client, wl); err != nil {
+		return err
+	}
+
 	// namespace
 	if !NamespaceExists(client, wl.Namespace) {
 		if err := buildNamespace(client, wl); err != nil {
@@ -26,6 +30,11 @@ func CreateSlurm(client kubernetes.Interface, wl *v1s.Slik) error {
 		return err
 	}
 
+	// sssd.conf and nsswitch.conf
+	if err := buildIdentityConfig(client, wl); err != nil {
+		return err
+	}
+
 	// slurm.conf
 	if err := buildSlurmconfConfigMap(client, wl); err != nil {
 		return err
@@ -67,20 +76,26 @@ func CreateSlurm(client kubernetes.Interface, wl *v1s.Slik) error {
 	}
 
 	// slurmctld
-	if err := buildSlurmctlDeployment(client, wl); err != nil {
-		return err
+	if wl.Spec.Slurmctld.HighAvailability {
+		if err := buildSlurmctldHA(client, wl); err != nil {
+			return err
+		}
+	} else {
+		if err := buildSlurmctlDeployment(client, wl); err != nil {
+			return err
+		}
 	}
 
 	if err := buildSlurmctlService(client, wl); err != nil {
 		return err
 	}
 
-	// slurmd
-	if err := buildSlurmdDeployments(client, wl); err != nil {
+	// slurmd, the pods are run by the SlurmNodeSet or the compute pools
+	if err := buildSlurmdService(client, wl); err != nil {
 		return err
 	}
 
-	if err := buildSlurmdService(client, wl); err != nil {
+	if err := buildComputePools(client, wl); err != nil {
 		return err
 	}
 
@@ -89,6 +104,21 @@ func CreateSlurm(client kubernetes.Interface, wl *v1s.Slik) error {
 		return err
 	}
 
+	// login
+	if wl.Spec.Login.Enabled {
+		if err := buildLoginHostKeysSecret(client, wl); err != nil {
+			return err
+		}
+
+		if err := buildLoginDeployment(client, wl); err != nil {
+			return err
+		}
+
+		if err := buildLoginService(client, wl); err != nil {
+			return err
+		}
+	}
+
 	// slurmrestd
 	if wl.Spec.Slurmdbd && wl.Spec.Slurmrestd {
 		if err := buildSlurmrestdDeployment(client, wl); err != nil {
@@ -100,7 +130,7 @@ func CreateSlurm(client kubernetes.Interface, wl *v1s.Slik) error {
 		}
 	}
 
-	if err := reconcileDisabledComponents(client, wl.Name, wl.Namespace, wl.Spec.Slurmdbd, wl.Spec.Slurmrestd); err != nil {
+	if err := reconcileDisabledComponents(client, wl); err != nil {
 		return err
 	}
 
@@ -0,0 +1,315 @@
+package slurm
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/hostkeys"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+func loginReplicas(wl *v1s.Slik) int32 {
+	if !wl.Spec.Login.Enabled {
+		return 0
+	}
+
+	if wl.Spec.Login.Replicas == 0 {
+		return 1
+	}
+
+	return wl.Spec.Login.Replicas
+}
+
+// buildLoginHostKeysSecret creates the ssh host keys secret, existing keys are never replaced
+func buildLoginHostKeysSecret(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	name := fmt.Sprintf("%s-login-hostkeys", wl.Name)
+	if SecretExists(client, name, wl.Namespace) {
+		return nil
+	}
+
+	keys, err := hostkeys.NewHostKeys()
+	if err != nil {
+		return err
+	}
+
+	secretSpec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: keys,
+	}
+
+	log.Infof("secret (login host keys): %s", name)
+
+	return applySecret(client, secretSpec)
+}
+
+func buildLoginDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	aff, err := mkAffinity(wl)
+	if err != nil {
+		return err
+	}
+
+	mungeCont := mkMungeContainer(wl)
+	loginCont := mkLoginContainer(wl)
+	annotations := configChecksumAnnotations(client, wl.Namespace,
+		fmt.Sprintf("%s-munged", wl.Name),
+	)
+	maps.Copy(annotations, slurmConfChecksumAnnotations(client, wl))
+
+	log.Infof("munged container: %+v", *mungeCont)
+	log.Infof("login container: %+v", *loginCont)
+
+	if aff != nil {
+		log.Infof("affinity: %+v", *aff)
+	}
+
+	replicas := loginReplicas(wl)
+	var hostKeysMode int32 = 0o600
+
+	loginDep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-login", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-login", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": fmt.Sprintf("%s-login", wl.Name),
+				},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        wl.Name,
+					Namespace:   wl.Namespace,
+					Annotations: annotations,
+					Labels: map[string]string{
+						"app":                          fmt.Sprintf("%s-login", wl.Name),
+						"app.kubernetes.io/managed-by": "slik",
+					},
+				},
+				Spec: v1.PodSpec{
+					Affinity: aff,
+					InitContainers: []v1.Container{
+						*mungeCont,
+					},
+					Containers: []v1.Container{
+						*loginCont,
+					},
+					RestartPolicy:    v1.RestartPolicyAlways,
+					ImagePullSecrets: []v1.LocalObjectReference{},
+					Volumes: []v1.Volume{
+						{
+							Name: "shared-data",
+							VolumeSource: v1.VolumeSource{
+								EmptyDir: &v1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "munge",
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{
+										Name: fmt.Sprintf("%s-munged", wl.Name),
+									},
+								},
+							},
+						},
+						{
+							Name: "slurm",
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{
+										Name: fmt.Sprintf("%s-slurm", wl.Name),
+									},
+								},
+							},
+						},
+						{
+							Name: "hostkeys",
+							VolumeSource: v1.VolumeSource{
+								Secret: &v1.SecretVolumeSource{
+									SecretName:  fmt.Sprintf("%s-login-hostkeys", wl.Name),
+									DefaultMode: &hostKeysMode,
+								},
+							},
+						},
+						{
+							Name: "authorized-keys",
+							VolumeSource: v1.VolumeSource{
+								Projected: mkAuthorizedKeysVolume(wl),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	withSharedVolumes(&loginDep.Spec.Template.Spec, wl, v1s.ComponentLogin, "login")
+	withIdentity(client, &loginDep.Spec.Template, wl, "login")
+	withClientConfServer(&loginDep.Spec.Template, wl, "login")
+
+	log.Infof("login deployment: %+v", loginDep)
+
+	if err := applyDeployment(client, loginDep); err != nil {
+		return err
+	}
+
+	log.Infof("login deployment %s created", wl.Name)
+
+	return nil
+}
+
+// mkAuthorizedKeysVolume projects every user's authorized_keys to /etc/ssh/authorized_keys/<user>
+func mkAuthorizedKeysVolume(wl *v1s.Slik) *v1.ProjectedVolumeSource {
+	var mode int32 = 0o644
+
+	projected := &v1.ProjectedVolumeSource{
+		Sources:     []v1.VolumeProjection{},
+		DefaultMode: &mode,
+	}
+
+	for i := range wl.Spec.Login.AuthorizedKeys {
+		ak := &wl.Spec.Login.AuthorizedKeys[i]
+
+		switch {
+		case ak.ConfigMapKeyRef != nil:
+			projected.Sources = append(projected.Sources, v1.VolumeProjection{
+				ConfigMap: &v1.ConfigMapProjection{
+					LocalObjectReference: ak.ConfigMapKeyRef.LocalObjectReference,
+					Items: []v1.KeyToPath{
+						{Key: ak.ConfigMapKeyRef.Key, Path: ak.User},
+					},
+					Optional: ak.ConfigMapKeyRef.Optional,
+				},
+			})
+		case ak.SecretKeyRef != nil:
+			projected.Sources = append(projected.Sources, v1.VolumeProjection{
+				Secret: &v1.SecretProjection{
+					LocalObjectReference: ak.SecretKeyRef.LocalObjectReference,
+					Items: []v1.KeyToPath{
+						{Key: ak.SecretKeyRef.Key, Path: ak.User},
+					},
+					Optional: ak.SecretKeyRef.Optional,
+				},
+			})
+		}
+	}
+
+	return projected
+}
+
+func mkLoginContainer(wl *v1s.Slik) *v1.Container {
+	c := v1.Container{
+		Name:  "login",
+		Image: config.GetSlurmLoginImage(),
+	}
+
+	c.VolumeMounts = []v1.VolumeMount{
+		{
+			Name:      "munge",
+			MountPath: "/etc/munge",
+		},
+		{
+			Name:      "slurm",
+			MountPath: "/etc/slurm",
+		},
+		{
+			Name:      "shared-data",
+			MountPath: "/run/munge",
+		},
+		{
+			Name:      "hostkeys",
+			MountPath: "/etc/ssh/hostkeys",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "authorized-keys",
+			MountPath: "/etc/ssh/authorized_keys",
+			ReadOnly:  true,
+		},
+	}
+
+	c.Env = []v1.EnvVar{
+		{
+			Name:  "X_VULTR_SLURM_ID",
+			Value: wl.Name,
+		},
+	}
+
+	c.Ports = []v1.ContainerPort{
+		{
+			Name:          "ssh",
+			ContainerPort: 22,
+		},
+	}
+
+	return &c
+}
+
+func buildLoginService(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	svcType := wl.Spec.Login.ServiceType
+	if svcType == "" {
+		svcType = v1.ServiceTypeLoadBalancer
+	}
+
+	svcSpec := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-login", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-login", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type: svcType,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "ssh",
+					Port:       22,
+					Protocol:   v1.ProtocolTCP,
+					TargetPort: intstr.FromString("ssh"),
+				},
+			},
+			Selector: map[string]string{
+				"app": fmt.Sprintf("%s-login", wl.Name),
+			},
+		},
+	}
+
+	log.Infof("login service: %+v", svcSpec)
+
+	if err := applyService(client, svcSpec); err != nil {
+		return err
+	}
+
+	log.Infof("login service %s created", wl.Name)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package slurm
+
+import (
+	"bytes"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildLogin(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Login: v1s.Login{
+				Enabled: true,
+				AuthorizedKeys: []v1s.AuthorizedKeys{
+					{
+						User: "alice",
+						ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "alice-keys"},
+							Key:                  "authorized_keys",
+						},
+					},
+					{
+						User: "bob",
+						SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "bob-keys"},
+							Key:                  "authorized_keys",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := buildLoginHostKeysSecret(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := GetSecret(client, "test-login-hostkeys", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"ssh_host_ed25519_key", "ssh_host_ed25519_key.pub", "ssh_host_ecdsa_key", "ssh_host_ecdsa_key.pub"} {
+		if len(secret.Data[key]) == 0 {
+			t.Fatalf("expected host key %s", key)
+		}
+	}
+
+	// host keys must survive reconciliation
+	if err := buildLoginHostKeysSecret(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := GetSecret(client, "test-login-hostkeys", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(secret.Data["ssh_host_ed25519_key"], again.Data["ssh_host_ed25519_key"]) {
+		t.Fatal("expected host keys to be preserved")
+	}
+
+	if err := buildLoginDeployment(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	dep, err := GetDeployment(client, "test-login", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *dep.Spec.Replicas != 1 {
+		t.Fatalf("expected 1 login replica, got %d", *dep.Spec.Replicas)
+	}
+
+	var projected *v1.ProjectedVolumeSource
+	for i := range dep.Spec.Template.Spec.Volumes {
+		if dep.Spec.Template.Spec.Volumes[i].Name == "authorized-keys" {
+			projected = dep.Spec.Template.Spec.Volumes[i].Projected
+		}
+	}
+
+	if projected == nil || len(projected.Sources) != 2 ||
+		projected.Sources[0].ConfigMap.Items[0].Path != "alice" ||
+		projected.Sources[1].Secret.Items[0].Path != "bob" {
+		t.Fatalf("unexpected authorized keys volume: %+v", projected)
+	}
+
+	if err := buildLoginService(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	svc, err := GetService(client, "test-login", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Fatalf("expected LoadBalancer login service, got %s", svc.Spec.Type)
+	}
+}
@@ -1,13 +1,13 @@
 package slurm
 
 import (
-	"bytes"
 	"fmt"
-	"html/template"
+	"maps"
+	"slices"
 	"strconv"
-	"strings"
 
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
 
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
@@ -15,32 +15,16 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// SlurmConf configuration for generation of slurm.conf
-type SlurmConf struct {
-	SlikName string
-
-	SlurmdNodes []SlurmdNode
-	Slurmdbd    bool
-}
-
-// SlurmdNode for generation of the nodes section in slurm.conf
-type SlurmdNode struct {
-	NodeName       string
-	CPUs           int
-	ThreadsPerCore int
-	RealMemory     int
-}
-
-// NewSlurmConf bilds SlurmConf for templating out slurm.conf
-func NewSlurmConf(client kubernetes.Interface, wl *v1s.Slik) (*SlurmConf, error) {
+// NewSlurmConf bilds the slurmconf model for templating out slurm.conf
+func NewSlurmConf(client kubernetes.Interface, wl *v1s.Slik) (*slurmconf.Slurm, error) {
 	log := zap.L().Sugar()
 
-	nodes, err := slurmNodes(client)
+	nodes, err := slurmNodes(client, wl)
 	if err != nil {
 		return nil, err
 	}
 
-	var conf SlurmConf
+	var conf slurmconf.Slurm
 	for i := range nodes {
 		labels := nodes[i].GetLabels()
 		cpusS := labels[nodeLabelCPUs]
@@ -62,18 +46,69 @@ func NewSlurmConf(client kubernetes.Interface, wl *v1s.Slik) (*SlurmConf, error)
 			return nil, err
 		}
 
-		log.Infof("Node: %s, CPU: %d, Memory: %d, ThreadsPerCore: %d", nodes[i].Name, cpus, memory, threadsPerCore)
-
-		conf.SlurmdNodes = append(conf.SlurmdNodes, SlurmdNode{
-			NodeName:       nodes[i].Name,
-			CPUs:           cpus,
-			ThreadsPerCore: threadsPerCore,
-			RealMemory:     memory,
+		boards, socketsPerBoard, coresPerSocket := nodeTopology(&nodes[i], cpus, threadsPerCore)
+		gpus, gpuType, gpuFiles := nodeGPUs(&nodes[i])
+
+		log.Infof("Node: %s, CPU: %d, Memory: %d, Boards: %d, SocketsPerBoard: %d, CoresPerSocket: %d, ThreadsPerCore: %d, GPUs: %d",
+			nodes[i].Name, cpus, memory, boards, socketsPerBoard, coresPerSocket, threadsPerCore, gpus)
+
+		conf.SlurmdNodes = append(conf.SlurmdNodes, slurmconf.Node{
+			NodeName:        nodes[i].Name,
+			CPUs:            cpus,
+			Boards:          boards,
+			SocketsPerBoard: socketsPerBoard,
+			CoresPerSocket:  coresPerSocket,
+			ThreadsPerCore:  threadsPerCore,
+			RealMemory:      memory,
+			GPUs:            gpus,
+			GPUType:         gpuType,
+			GPUCount:        !gpuFiles,
+			Features:        nodeFeatures(wl, &nodes[i]),
 		})
+
+		conf.Gres = conf.Gres || gpus > 0
 	}
 
 	conf.SlikName = wl.Name
+	conf.SlurmctldHosts = slurmctldHosts(wl)
 	conf.Slurmdbd = wl.Spec.Slurmdbd
+	conf.Configless = wl.Spec.Configless
+
+	if cgroups := wl.Spec.Cgroups; cgroups.Enabled {
+		conf.Cgroups = &slurmconf.Cgroups{
+			ConstrainCores:    cgroups.ConstrainCores,
+			ConstrainRAMSpace: cgroups.ConstrainRAMSpace,
+			ConstrainDevices:  cgroups.ConstrainDevices,
+		}
+	}
+
+	conf.Scripts = scriptParameters(wl)
+	conf.Extra = confParameters(wl.Spec.SlurmConf.Extra)
+	for i := range wl.Spec.SlurmConf.Includes {
+		conf.Includes = append(conf.Includes, includeFileName(&wl.Spec.SlurmConf.Includes[i]))
+	}
+
+	poolNodes, partitions := computePoolNodes(wl)
+	conf.SlurmdNodes = append(conf.SlurmdNodes, poolNodes...)
+	conf.Partitions = partitions
+	conf.Maintenance = wl.Spec.Maintenance
+
+	if elasticEnabled(wl) {
+		conf.Elastic = &slurmconf.Elastic{
+			SuspendTime:   wl.Spec.Elastic.SuspendTime,
+			ResumeTimeout: wl.Spec.Elastic.ResumeTimeout,
+		}
+		conf.ElasticNodes = elasticSlurmdNodes(wl)
+	}
+
+	if topologyEnabled(wl) {
+		other := []string{}
+		for _, node := range slices.Concat(poolNodes, conf.ElasticNodes) {
+			other = append(other, fmt.Sprintf("%s-%s", wl.Name, node.NodeName))
+		}
+
+		conf.Topology = topologySwitches(wl, nodes, other)
+	}
 
 	log.Infof("slurmconf: %+v", conf)
 
@@ -89,18 +124,48 @@ func buildSlurmconfConfigMap(client kubernetes.Interface, wl *v1s.Slik) error {
 		return err
 	}
 
-	tpl, err := template.New("slurm_conf").Funcs(
-		template.FuncMap{"StringsJoin": strings.Join},
-	).Parse(slurmConfTpl)
+	slurmConf, err := slurmconf.RenderSlurmConf(conf)
 	if err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
-	if err := tpl.Execute(&buf, *conf); err != nil {
+	data := map[string]string{
+		"slurm.conf": slurmConf,
+	}
+
+	// read by slurmd from the same directory, or fetched from slurmctld in configless mode
+	if conf.Gres {
+		if data["gres.conf"], err = slurmconf.RenderGresConf(conf); err != nil {
+			return err
+		}
+	}
+
+	includes, err := confIncludes(client, wl, &wl.Spec.SlurmConf, slurmConfOwnedKeys)
+	if err != nil {
 		return err
 	}
 
+	maps.Copy(data, includes)
+
+	// the lua job submit plugin loads job_submit.lua from the directory of slurm.conf
+	if ref := wl.Spec.Scripts.JobSubmit; ref != nil {
+		if data["job_submit.lua"], err = configMapKey(client, wl.Namespace, ref.ConfigMap, ref.Key); err != nil {
+			return err
+		}
+	}
+
+	if conf.Cgroups != nil {
+		if data["cgroup.conf"], err = slurmconf.RenderCgroupConf(conf); err != nil {
+			return err
+		}
+	}
+
+	if len(conf.Topology) > 0 {
+		if data["topology.conf"], err = slurmconf.RenderTopologyConf(conf); err != nil {
+			return err
+		}
+	}
+
 	name := fmt.Sprintf("%s-slurm", wl.Name)
 	cmSpec := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -110,9 +175,7 @@ func buildSlurmconfConfigMap(client kubernetes.Interface, wl *v1s.Slik) error {
 				"app.kubernetes.io/managed-by": "slik",
 			},
 		},
-		Data: map[string]string{
-			"slurm.conf": buf.String(),
-		},
+		Data: data,
 	}
 
 	log.Infof("configmap (slurm.conf): %+v", cmSpec)
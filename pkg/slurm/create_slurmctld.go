@@ -2,6 +2,7 @@ package slurm
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/vultr/slik/cmd/slik/config"
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
@@ -17,17 +18,61 @@ import (
 func buildSlurmctlDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
 	log := zap.L().Sugar()
 
-	aff, err := mkAffinity(wl)
+	podTemplate, err := mkSlurmctldPodTemplate(client, wl)
 	if err != nil {
 		return err
 	}
 
+	// MUST be set or slurmctld will NOT start
+	podTemplate.Spec.Hostname = fmt.Sprintf("%s-slurmctld", wl.Name)
+
+	var replicas int32 = 1
+
+	depSpec := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmctld", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": fmt.Sprintf("%s-slurmctld", wl.Name),
+				},
+			},
+			Template: *podTemplate,
+		},
+	}
+
+	log.Infof("deployment: %+v", depSpec)
+
+	if err := applyDeployment(client, depSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurm %s created", wl.Name)
+
+	return nil
+}
+
+func mkSlurmctldPodTemplate(client kubernetes.Interface, wl *v1s.Slik) (*v1.PodTemplateSpec, error) {
+	log := zap.L().Sugar()
+
+	aff, err := mkAffinity(wl)
+	if err != nil {
+		return nil, err
+	}
+
 	mungeCont := mkMungeContainer(wl)
 	slurmctlCont := mkSlurmctlContainer(wl)
 	annotations := configChecksumAnnotations(client, wl.Namespace,
 		fmt.Sprintf("%s-munged", wl.Name),
-		fmt.Sprintf("%s-slurm", wl.Name),
 	)
+	maps.Copy(annotations, slurmConfChecksumAnnotations(client, wl))
 
 	log.Infof("munged container: %+v", *mungeCont)
 	log.Infof("slurmctld container: %+v", *slurmctlCont)
@@ -36,70 +81,49 @@ func buildSlurmctlDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
 		log.Infof("affinity: %+v", *aff)
 	}
 
-	var replicas int32 = 1
-
-	depSpec := &appsv1.Deployment{
+	podTemplate := &v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-slurmctld", wl.Name),
-			Namespace: wl.Namespace,
+			Name:        fmt.Sprintf("%s-slurmctld", wl.Name),
+			Namespace:   wl.Namespace,
+			Annotations: annotations,
 			Labels: map[string]string{
 				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
 				"app.kubernetes.io/managed-by": "slik",
 			},
 		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": fmt.Sprintf("%s-slurmctld", wl.Name),
-				},
+		Spec: v1.PodSpec{
+			Affinity: aff,
+			InitContainers: []v1.Container{
+				*mungeCont,
+			},
+			Containers: []v1.Container{
+				*slurmctlCont,
 			},
-			Template: v1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        fmt.Sprintf("%s-slurmctld", wl.Name),
-					Namespace:   wl.Namespace,
-					Annotations: annotations,
-					Labels: map[string]string{
-						"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
-						"app.kubernetes.io/managed-by": "slik",
+			RestartPolicy:    v1.RestartPolicyAlways,
+			ImagePullSecrets: []v1.LocalObjectReference{},
+			Volumes: []v1.Volume{
+				{
+					Name: "shared-data",
+					VolumeSource: v1.VolumeSource{
+						EmptyDir: &v1.EmptyDirVolumeSource{},
 					},
 				},
-				Spec: v1.PodSpec{
-					Hostname: fmt.Sprintf("%s-slurmctld", wl.Name), // MUST be set or slurmctld will NOT start
-					Affinity: aff,
-					InitContainers: []v1.Container{
-						*mungeCont,
-					},
-					Containers: []v1.Container{
-						*slurmctlCont,
-					},
-					RestartPolicy:    v1.RestartPolicyAlways,
-					ImagePullSecrets: []v1.LocalObjectReference{},
-					Volumes: []v1.Volume{
-						{
-							Name: "shared-data",
-							VolumeSource: v1.VolumeSource{
-								EmptyDir: &v1.EmptyDirVolumeSource{},
-							},
-						},
-						{
-							Name: "munge",
-							VolumeSource: v1.VolumeSource{
-								ConfigMap: &v1.ConfigMapVolumeSource{
-									LocalObjectReference: v1.LocalObjectReference{
-										Name: fmt.Sprintf("%s-munged", wl.Name),
-									},
-								},
+				{
+					Name: "munge",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: fmt.Sprintf("%s-munged", wl.Name),
 							},
 						},
-						{
-							Name: "slurm",
-							VolumeSource: v1.VolumeSource{
-								ConfigMap: &v1.ConfigMapVolumeSource{
-									LocalObjectReference: v1.LocalObjectReference{
-										Name: fmt.Sprintf("%s-slurm", wl.Name),
-									},
-								},
+					},
+				},
+				{
+					Name: "slurm",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: fmt.Sprintf("%s-slurm", wl.Name),
 							},
 						},
 					},
@@ -108,15 +132,12 @@ func buildSlurmctlDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
 		},
 	}
 
-	log.Infof("deployment: %+v", depSpec)
-
-	if err := applyDeployment(client, depSpec); err != nil {
-		return err
-	}
-
-	log.Infof("slurm %s created", wl.Name)
+	withSharedVolumes(&podTemplate.Spec, wl, v1s.ComponentSlurmctld, "slurmctld")
+	withIdentity(client, podTemplate, wl, "slurmctld")
+	withPowerSecret(podTemplate, wl, "slurmctld")
+	withScripts(client, podTemplate, wl, v1s.ComponentSlurmctld, "slurmctld")
 
-	return nil
+	return podTemplate, nil
 }
 
 func mkSlurmctlContainer(wl *v1s.Slik) *v1.Container {
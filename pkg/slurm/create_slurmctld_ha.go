@@ -0,0 +1,270 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// slurmctldHosts returns the SlurmctldHost entries for slurm.conf, primary first
+func slurmctldHosts(wl *v1s.Slik) []string {
+	if !wl.Spec.Slurmctld.HighAvailability {
+		return []string{fmt.Sprintf("%s-slurmctld", wl.Name)}
+	}
+
+	hosts := []string{}
+	for i := 0; i < int(SlurmctldHAReplicas); i++ {
+		pod := fmt.Sprintf("%s-slurmctld-%d", wl.Name, i)
+
+		// hostname is the pod name, the address resolves through the headless service
+		hosts = append(hosts, fmt.Sprintf("%s(%s.%s-slurmctld-hosts)", pod, pod, wl.Name))
+	}
+
+	return hosts
+}
+
+// buildSlurmctldHA runs the primary and backup slurmctld as a StatefulSet
+func buildSlurmctldHA(client kubernetes.Interface, wl *v1s.Slik) error {
+	if err := buildSlurmctldStatePVC(client, wl); err != nil {
+		return err
+	}
+
+	if err := buildSlurmctldHeadlessService(client, wl); err != nil {
+		return err
+	}
+
+	if err := buildSlurmctldStatefulSet(client, wl); err != nil {
+		return err
+	}
+
+	return buildSlurmctldPodDisruptionBudget(client, wl)
+}
+
+func buildSlurmctldStatefulSet(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	podTemplate, err := mkSlurmctldPodTemplate(client, wl)
+	if err != nil {
+		return err
+	}
+
+	// never schedule the primary and the backup on the same node
+	podTemplate.Spec.Affinity = mkSlurmctldAntiAffinity(wl)
+	podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, v1.Volume{
+		Name: "slurmctld-state",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: fmt.Sprintf("%s-slurmctld-state", wl.Name),
+			},
+		},
+	})
+
+	for i := range podTemplate.Spec.Containers {
+		if podTemplate.Spec.Containers[i].Name != "slurmctld" {
+			continue
+		}
+
+		podTemplate.Spec.Containers[i].VolumeMounts = append(podTemplate.Spec.Containers[i].VolumeMounts, v1.VolumeMount{
+			Name:      "slurmctld-state",
+			MountPath: "/var/lib/slurm/slurmctld",
+		})
+	}
+
+	replicas := SlurmctldHAReplicas
+
+	stsSpec := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmctld", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         fmt.Sprintf("%s-slurmctld-hosts", wl.Name),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": fmt.Sprintf("%s-slurmctld", wl.Name),
+				},
+			},
+			Template: *podTemplate,
+		},
+	}
+
+	log.Infof("slurmctld statefulset: %+v", stsSpec)
+
+	if err := applyStatefulSet(client, stsSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurmctld statefulset %s created", wl.Name)
+
+	return nil
+}
+
+func mkSlurmctldAntiAffinity(wl *v1s.Slik) *v1.Affinity {
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": fmt.Sprintf("%s-slurmctld", wl.Name),
+						},
+					},
+					TopologyKey: "kubernetes.io/hostname",
+				},
+			},
+		},
+	}
+}
+
+// buildSlurmctldHeadlessService gives each slurmctld pod a stable dns name
+func buildSlurmctldHeadlessService(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	svcSpec := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmctld-hosts", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeClusterIP,
+			ClusterIP: v1.ClusterIPNone,
+			// the backup must be resolvable before it is ready
+			PublishNotReadyAddresses: true,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "slurmctld",
+					Port:       6817,
+					Protocol:   v1.ProtocolTCP,
+					TargetPort: intstr.FromString("slurmctld"),
+				},
+			},
+			Selector: map[string]string{
+				"app": fmt.Sprintf("%s-slurmctld", wl.Name),
+			},
+		},
+	}
+
+	log.Infof("slurmctld headless service: %+v", svcSpec)
+
+	if err := applyService(client, svcSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurmctld headless service %s created", wl.Name)
+
+	return nil
+}
+
+// stateStorageSize returns the size of the StateSaveLocation volume
+func stateStorageSize(wl *v1s.Slik) string {
+	if wl.Spec.Slurmctld.StateStorageSize == "" {
+		return SlurmctldStateStorageSize
+	}
+
+	return wl.Spec.Slurmctld.StateStorageSize
+}
+
+// buildSlurmctldStatePVC creates the RWX volume shared by primary and backup for StateSaveLocation
+func buildSlurmctldStatePVC(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	name := fmt.Sprintf("%s-slurmctld-state", wl.Name)
+
+	storageSize := stateStorageSize(wl)
+
+	size, err := resource.ParseQuantity(storageSize)
+	if err != nil {
+		return err
+	}
+
+	if PersistentVolumeClaimExists(client, name, wl.Namespace) {
+		return updatePVCStorage(client, name, wl.Namespace, storageSize)
+	}
+
+	pvcSpec := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{
+				v1.ReadWriteMany,
+			},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+
+	if wl.Spec.Slurmctld.StateStorageClass != "" {
+		pvcSpec.Spec.StorageClassName = &wl.Spec.Slurmctld.StateStorageClass
+	}
+
+	log.Infof("slurmctld state pvc: %+v", pvcSpec)
+
+	_, err = client.CoreV1().PersistentVolumeClaims(wl.Namespace).Create(context.TODO(), pvcSpec, metav1.CreateOptions{})
+
+	return ignoreAlreadyExists(err)
+}
+
+// buildSlurmctldPodDisruptionBudget keeps one controller up during voluntary disruptions
+func buildSlurmctldPodDisruptionBudget(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	minAvailable := intstr.FromInt32(1)
+
+	pdbSpec := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmctld", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmctld", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": fmt.Sprintf("%s-slurmctld", wl.Name),
+				},
+			},
+		},
+	}
+
+	log.Infof("slurmctld pdb: %+v", pdbSpec)
+
+	if err := applyPodDisruptionBudget(client, pdbSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurmctld pdb %s created", wl.Name)
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package slurm
+
+import (
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSlurmctldHosts(t *testing.T) {
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	hosts := slurmctldHosts(wl)
+	if len(hosts) != 1 || hosts[0] != "test-slurmctld" {
+		t.Fatalf("expected single controller host, got %v", hosts)
+	}
+
+	wl.Spec.Slurmctld.HighAvailability = true
+
+	hosts = slurmctldHosts(wl)
+	if len(hosts) != 2 ||
+		hosts[0] != "test-slurmctld-0(test-slurmctld-0.test-slurmctld-hosts)" ||
+		hosts[1] != "test-slurmctld-1(test-slurmctld-1.test-slurmctld-hosts)" {
+		t.Fatalf("expected primary and backup controller hosts, got %v", hosts)
+	}
+}
+
+func TestBuildSlurmctldHA(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Slurmctld: v1s.Slurmctld{
+				HighAvailability:  true,
+				StateStorageClass: "rwx",
+			},
+		},
+	}
+
+	if err := buildSlurmctldHA(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	sts, err := GetStatefulSet(client, "test-slurmctld", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *sts.Spec.Replicas != SlurmctldHAReplicas || sts.Spec.ServiceName != "test-slurmctld-hosts" {
+		t.Fatalf("unexpected statefulset spec: %+v", sts.Spec)
+	}
+
+	if sts.Spec.Template.Spec.Affinity == nil || sts.Spec.Template.Spec.Affinity.PodAntiAffinity == nil {
+		t.Fatal("expected anti-affinity between controllers")
+	}
+
+	pvc, err := GetPersistentVolumeClaim(client, "test-slurmctld-state", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pvc.Spec.AccessModes[0] != "ReadWriteMany" || *pvc.Spec.StorageClassName != "rwx" {
+		t.Fatalf("unexpected state pvc spec: %+v", pvc.Spec)
+	}
+
+	if !PodDisruptionBudgetExists(client, "test-slurmctld", "default") {
+		t.Fatal("expected slurmctld pdb")
+	}
+
+	// turning high availability off removes the statefulset and its companions
+	wl.Spec.Slurmctld.HighAvailability = false
+	if err := reconcileSlurmctldMode(client, wl.Name, wl.Namespace, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if StatefulsetExists(client, "test-slurmctld", "default") ||
+		ServiceExists(client, "test-slurmctld-hosts", "default") ||
+		PodDisruptionBudgetExists(client, "test-slurmctld", "default") ||
+		PersistentVolumeClaimExists(client, "test-slurmctld-state", "default") {
+		t.Fatal("expected high availability resources to be removed")
+	}
+}
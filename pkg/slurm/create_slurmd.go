@@ -2,178 +2,218 @@ package slurm
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/vultr/slik/cmd/slik/config"
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	clientv1 "github.com/vultr/slik/pkg/clientset/v1"
 
 	"go.uber.org/zap"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 )
 
+// buildSlurmdService creates the headless service giving every slurmd pod a stable <name>-<node>.<name>-slurmd address
 func buildSlurmdService(client kubernetes.Interface, wl *v1s.Slik) error {
 	log := zap.L().Sugar()
 
-	nodes, err := slurmNodes(client)
+	svcSpec := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmd", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeClusterIP,
+			ClusterIP: v1.ClusterIPNone,
+			// slurmctld has to reach slurmd before the pod is ready
+			PublishNotReadyAddresses: true,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "slurmd",
+					Port:       6818,
+					Protocol:   v1.ProtocolTCP,
+					TargetPort: intstr.FromString("slurmd"),
+				},
+			},
+			Selector: map[string]string{
+				"app": fmt.Sprintf("%s-slurmd", wl.Name),
+			},
+		},
+	}
+
+	log.Infof("slurmd service: %+v", svcSpec)
+
+	if err := applyService(client, svcSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurmd service %s created", wl.Name)
+
+	return nil
+}
+
+// BuildSlurmdNodeSet creates or updates the SlurmNodeSet running slurmd on every slurm node
+func BuildSlurmdNodeSet(client kubernetes.Interface, nodeSets clientv1.SlurmNodeSetInterface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	if computePoolsEnabled(wl) {
+		return DeleteSlurmdNodeSet(nodeSets, wl.Name)
+	}
+
+	tpl, err := mkSlurmdPodTemplate(client, wl)
 	if err != nil {
 		return err
 	}
 
-	for i := range nodes {
-		svcSpec := &v1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-%s", wl.Name, nodes[i].Name),
-				Namespace: wl.Namespace,
-				Labels: map[string]string{
-					"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
-					"app.kubernetes.io/managed-by": "slik",
-				},
+	nodeSetSpec := &v1s.SlurmNodeSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slurmd", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
 			},
-			Spec: v1.ServiceSpec{
-				Type: v1.ServiceTypeClusterIP,
-				Ports: []v1.ServicePort{
-					{
-						Name:       "slurmd",
-						Port:       6818,
-						Protocol:   v1.ProtocolTCP,
-						TargetPort: intstr.FromString("slurmd"),
-					},
-				},
-				Selector: map[string]string{
-					"app":  fmt.Sprintf("%s-slurmd", wl.Name),
-					"host": nodes[i].Name,
-				},
+		},
+		Spec: v1s.SlurmNodeSetSpec{
+			Cluster:  wl.Name,
+			Template: *tpl,
+			UpdateStrategy: v1s.SlurmNodeSetUpdateStrategy{
+				MaxUnavailable:             wl.Spec.Slurmd.MaxUnavailable,
+				MaxUnavailablePerPartition: wl.Spec.Slurmd.MaxUnavailablePerPartition,
 			},
-		}
+		},
+	}
+
+	log.Infof("slurmd nodeset: %+v", nodeSetSpec)
+
+	if err := applySlurmNodeSet(nodeSets, nodeSetSpec); err != nil {
+		return err
+	}
+
+	log.Infof("slurmd nodeset %s created", wl.Name)
 
-		log.Infof("slurmd service: %+v", svcSpec)
+	return nil
+}
 
-		if err := applyService(client, svcSpec); err != nil {
+func applySlurmNodeSet(nodeSets clientv1.SlurmNodeSetInterface, desired *v1s.SlurmNodeSet) error {
+	existing, err := nodeSets.Get(desired.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = nodeSets.Create(desired)
 			return err
 		}
 
-		log.Infof("slurmd service %s created", wl.Name)
+		return err
 	}
 
-	return nil
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	existing.Spec = desired.Spec
+	_, err = nodeSets.Update(existing, metav1.UpdateOptions{})
+
+	return err
 }
 
-func buildSlurmdDeployments(client kubernetes.Interface, wl *v1s.Slik) error {
+// DeleteSlurmdNodeSet deletes the slurmd SlurmNodeSet if it exists, its pods are garbage collected
+func DeleteSlurmdNodeSet(nodeSets clientv1.SlurmNodeSetInterface, name string) error {
 	log := zap.L().Sugar()
 
-	nodes, err := slurmNodes(client)
-	if err != nil {
+	err := nodeSets.Delete(fmt.Sprintf("%s-slurmd", name), metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
 
-	for i := range nodes {
-		aff, err := mkAffinity(wl)
-		if err != nil {
-			return err
-		}
+	log.Infof("slurmd nodeset %s deleted", name)
+
+	return nil
+}
 
-		mungeCont := mkMungeContainer(wl)
-		slurmdCont := mkSlurmdContainer(wl)
-		annotations := configChecksumAnnotations(client, wl.Namespace,
-			fmt.Sprintf("%s-munged", wl.Name),
-			fmt.Sprintf("%s-slurm", wl.Name),
-		)
+// mkSlurmdPodTemplate returns the slurmd pod template, the node specific fields are set by the SlurmNodeSet
+func mkSlurmdPodTemplate(client kubernetes.Interface, wl *v1s.Slik) (*v1.PodTemplateSpec, error) {
+	log := zap.L().Sugar()
 
-		log.Infof("munged container: %+v", *mungeCont)
-		log.Infof("slurmd container: %+v", *slurmdCont)
+	aff, err := mkAffinity(wl)
+	if err != nil {
+		return nil, err
+	}
 
-		if aff != nil {
-			log.Infof("affinity: %+v", *aff)
-		}
+	mungeCont := mkMungeContainer(wl)
+	slurmdCont := mkSlurmdContainer(wl)
+	annotations := configChecksumAnnotations(client, wl.Namespace,
+		fmt.Sprintf("%s-munged", wl.Name),
+	)
+	maps.Copy(annotations, slurmConfChecksumAnnotations(client, wl))
 
-		slurmDepSpec := &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-%s", wl.Name, nodes[i].Name),
-				Namespace: wl.Namespace,
-				Labels: map[string]string{
-					"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
-					"app.kubernetes.io/managed-by": "slik",
-					"host":                         nodes[i].Name,
-				},
+	log.Infof("munged container: %+v", *mungeCont)
+	log.Infof("slurmd container: %+v", *slurmdCont)
+
+	if aff != nil {
+		log.Infof("affinity: %+v", *aff)
+	}
+
+	podTemplate := &v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: annotations,
+			Labels: map[string]string{
+				"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
+				"app.kubernetes.io/managed-by": "slik",
 			},
-			Spec: appsv1.DeploymentSpec{
-				Selector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{
-						"app":  fmt.Sprintf("%s-slurmd", wl.Name),
-						"host": nodes[i].Name,
+		},
+		Spec: v1.PodSpec{
+			Affinity: aff,
+			InitContainers: []v1.Container{
+				*mungeCont,
+			},
+			Containers: []v1.Container{
+				*slurmdCont,
+			},
+			RestartPolicy:    v1.RestartPolicyAlways,
+			ImagePullSecrets: []v1.LocalObjectReference{},
+			Volumes: []v1.Volume{
+				{
+					Name: "shared-data",
+					VolumeSource: v1.VolumeSource{
+						EmptyDir: &v1.EmptyDirVolumeSource{},
 					},
 				},
-				Template: v1.PodTemplateSpec{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:        wl.Name,
-						Namespace:   wl.Namespace,
-						Annotations: annotations,
-						Labels: map[string]string{
-							"app":                          fmt.Sprintf("%s-slurmd", wl.Name),
-							"app.kubernetes.io/managed-by": "slik",
-							"host":                         nodes[i].Name,
+				{
+					Name: "munge",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: fmt.Sprintf("%s-munged", wl.Name),
+							},
 						},
 					},
-					Spec: v1.PodSpec{
-						Hostname: fmt.Sprintf("%s-%s", wl.Name, nodes[i].Name),
-						Affinity: aff,
-						InitContainers: []v1.Container{
-							*mungeCont,
-						},
-						Containers: []v1.Container{
-							*slurmdCont,
-						},
-						RestartPolicy:    v1.RestartPolicyAlways,
-						ImagePullSecrets: []v1.LocalObjectReference{},
-						Volumes: []v1.Volume{
-							{
-								Name: "shared-data",
-								VolumeSource: v1.VolumeSource{
-									EmptyDir: &v1.EmptyDirVolumeSource{},
-								},
-							},
-							{
-								Name: "munge",
-								VolumeSource: v1.VolumeSource{
-									ConfigMap: &v1.ConfigMapVolumeSource{
-										LocalObjectReference: v1.LocalObjectReference{
-											Name: fmt.Sprintf("%s-munged", wl.Name),
-										},
-									},
-								},
-							},
-							{
-								Name: "slurm",
-								VolumeSource: v1.VolumeSource{
-									ConfigMap: &v1.ConfigMapVolumeSource{
-										LocalObjectReference: v1.LocalObjectReference{
-											Name: fmt.Sprintf("%s-slurm", wl.Name),
-										},
-									},
-								},
+				},
+				{
+					Name: "slurm",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: fmt.Sprintf("%s-slurm", wl.Name),
 							},
 						},
-						NodeSelector: map[string]string{
-							"kubernetes.io/hostname": nodes[i].Name,
-						},
 					},
 				},
 			},
-		}
-
-		log.Infof("slurmd deployment: %+v", slurmDepSpec)
-
-		if err := applyDeployment(client, slurmDepSpec); err != nil {
-			return err
-		}
+		},
 	}
 
-	log.Infof("slurmd deployments %s created", wl.Name)
+	withSharedVolumes(&podTemplate.Spec, wl, v1s.ComponentSlurmd, "slurmd")
+	withIdentity(client, podTemplate, wl, "slurmd")
+	withConfServer(podTemplate, wl, "slurmd")
+	withCgroups(podTemplate, wl, "slurmd")
+	withScripts(client, podTemplate, wl, v1s.ComponentSlurmd, "slurmd")
 
-	return nil
+	return podTemplate, nil
 }
 
 func mkSlurmdContainer(wl *v1s.Slik) *v1.Container {
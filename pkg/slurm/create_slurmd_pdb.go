@@ -0,0 +1,133 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// evictableNodeStates slurm node states without running jobs
+var evictableNodeStates = []string{"idle", "drained", "down", "unknown"}
+
+// UpdateSlurmdDisruptionBudgets maintains a PodDisruptionBudget per slurmd of a kubernetes node or compute pool
+// that only allows eviction while slurm reports the node as idle or drained, so node upgrades wait for running
+// jobs. Budgets of slurmd pods that are gone are deleted.
+func UpdateSlurmdDisruptionBudgets(client kubernetes.Interface, exec Executor, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	nodes, err := slurmNodes(client, wl)
+	if err != nil {
+		return err
+	}
+
+	states, err := slurmNodeStates(client, exec, wl)
+	if err != nil {
+		return err
+	}
+
+	app := fmt.Sprintf("%s-slurmd", wl.Name)
+
+	// pod labels selecting each slurmd by its slurm node name
+	selectors := map[string]map[string]string{}
+	for i := range nodes {
+		selectors[fmt.Sprintf("%s-%s", wl.Name, nodes[i].Name)] = map[string]string{"app": app, "host": nodes[i].Name}
+	}
+
+	poolNodes, _ := computePoolNodes(wl)
+	for i := range poolNodes {
+		name := fmt.Sprintf("%s-%s", wl.Name, poolNodes[i].NodeName)
+		selectors[name] = map[string]string{"app": app, "statefulset.kubernetes.io/pod-name": name}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(selectors)) {
+		// nodes unknown to slurm have not registered and run no jobs
+		maxUnavailable := intstr.FromInt32(1)
+		if state, ok := states[name]; ok && !slices.Contains(evictableNodeStates, state) {
+			maxUnavailable = intstr.FromInt32(0)
+		}
+
+		labels := map[string]string{
+			"app":                          app,
+			"app.kubernetes.io/managed-by": "slik",
+		}
+		if host, ok := selectors[name]["host"]; ok {
+			labels["host"] = host
+		}
+
+		alwaysAllow := policyv1.AlwaysAllow
+		pdbSpec := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: wl.Namespace,
+				Labels:    labels,
+			},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MaxUnavailable: &maxUnavailable,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: selectors[name],
+				},
+				// a crashing slurmd runs no jobs, do not block node maintenance on it
+				UnhealthyPodEvictionPolicy: &alwaysAllow,
+			},
+		}
+
+		log.Infof("slurmd pdb: %+v", pdbSpec)
+
+		if err := applyPodDisruptionBudget(client, pdbSpec); err != nil {
+			return err
+		}
+	}
+
+	// nodes removed, drained or moved to compute pools would keep blocking evictions
+	existing, err := client.PolicyV1().PodDisruptionBudgets(wl.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s", app),
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range existing.Items {
+		if _, ok := selectors[existing.Items[i].Name]; ok {
+			continue
+		}
+
+		if err := PodDisruptionBudgetDelete(client, existing.Items[i].Name, wl.Namespace); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("slurmd pdbs %s updated", wl.Name)
+
+	return nil
+}
+
+// slurmNodeStates returns the base state of every slurm node, e.g. idle, mixed or drained
+func slurmNodeStates(client kubernetes.Interface, exec Executor, wl *v1s.Slik) (map[string]string, error) {
+	out, err := slurmCommand(client, exec, wl, "sinfo", "--noheader", "--Node", "--format=%N %T")
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]string{}
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// strip flags such as * (not responding) or ~ (powered off)
+		states[fields[0]] = strings.ToLower(strings.TrimRight(fields[1], "*~#!%$@^-"))
+	}
+
+	return states, nil
+}
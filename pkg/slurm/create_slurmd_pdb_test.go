@@ -0,0 +1,115 @@
+package slurm
+
+import (
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUpdateSlurmdDisruptionBudgets(t *testing.T) {
+	labels := map[string]string{
+		nodeLabelCPUs:           "2",
+		nodeLabelRealMemory:     "1024",
+		nodeLabelThreadsPerCore: "1",
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "busy", Labels: labels}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "idle", Labels: labels}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "drained", Labels: labels}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "new", Labels: labels}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	exec := &fakeExecutor{outputs: map[string]string{
+		"sinfo": "test-busy mixed\ntest-idle idle~\ntest-drained drained*\n",
+	}}
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	if err := UpdateSlurmdDisruptionBudgets(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	for node, expected := range map[string]int{"busy": 0, "idle": 1, "drained": 1, "new": 1} {
+		pdb, err := GetPodDisruptionBudget(client, "test-"+node, "default")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pdb.Spec.MaxUnavailable.IntValue() != expected {
+			t.Errorf("node %s: expected maxUnavailable %d, got %s", node, expected, pdb.Spec.MaxUnavailable)
+		}
+
+		if pdb.Spec.Selector.MatchLabels["host"] != node {
+			t.Errorf("node %s: unexpected selector %v", node, pdb.Spec.Selector.MatchLabels)
+		}
+	}
+}
+
+func TestUpdateSlurmdDisruptionBudgetsComputePools(t *testing.T) {
+	stale := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-removed",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-slurmd"},
+		},
+	}
+
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{
+			nodeLabelCPUs:           "2",
+			nodeLabelRealMemory:     "1024",
+			nodeLabelThreadsPerCore: "1",
+		}}},
+		stale,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	exec := &fakeExecutor{outputs: map[string]string{"sinfo": "test-small-0 allocated\n"}}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			ComputePools: []v1s.ComputePool{{Name: "small", Replicas: 2}},
+		},
+	}
+
+	if err := UpdateSlurmdDisruptionBudgets(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	// compute pools replace the slurmd of node a
+	if PodDisruptionBudgetExists(client, "test-removed", "default") || PodDisruptionBudgetExists(client, "test-a", "default") {
+		t.Fatal("expected the budgets of removed slurmd pods to be deleted")
+	}
+
+	for node, expected := range map[string]int{"small-0": 0, "small-1": 1} {
+		pdb, err := GetPodDisruptionBudget(client, "test-"+node, "default")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pdb.Spec.MaxUnavailable.IntValue() != expected ||
+			pdb.Spec.Selector.MatchLabels["statefulset.kubernetes.io/pod-name"] != "test-"+node {
+			t.Errorf("node %s: unexpected budget %+v", node, pdb.Spec)
+		}
+	}
+}
@@ -1,12 +1,11 @@
 package slurm
 
 import (
-	"bytes"
 	"fmt"
-	"html/template"
-	"strings"
+	"maps"
 
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
 
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
@@ -14,23 +13,20 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// SlurmdbConf slurmdb conf
-type SlurmdbConf struct {
-	SlikName string
-
-	User string
-	Pass string
-}
-
-// NewSlurmdbdConf bilds SlurmdbConf for templating out slurmdb.conf
-func NewSlurmdbdConf(client kubernetes.Interface, wl *v1s.Slik) (*SlurmdbConf, error) {
+// NewSlurmdbdConf bilds the slurmconf model for templating out slurmdb.conf
+func NewSlurmdbdConf(client kubernetes.Interface, wl *v1s.Slik) (*slurmconf.Slurmdbd, error) {
 	log := zap.L().Sugar()
 
-	var conf SlurmdbConf
+	var conf slurmconf.Slurmdbd
 	conf.SlikName = wl.Name
 	conf.User = "slurm"
 	conf.Pass = "slurm"
 
+	conf.Extra = confParameters(wl.Spec.SlurmdbdConf.Extra)
+	for i := range wl.Spec.SlurmdbdConf.Includes {
+		conf.Includes = append(conf.Includes, includeFileName(&wl.Spec.SlurmdbdConf.Includes[i]))
+	}
+
 	log.Infof("slurmdbconf: %+v", conf)
 
 	return &conf, nil
@@ -45,18 +41,22 @@ func buildSlurmdbdConfigMap(client kubernetes.Interface, wl *v1s.Slik) error {
 		return err
 	}
 
-	tpl, err := template.New("slurmdbd_conf").Funcs(
-		template.FuncMap{"StringsJoin": strings.Join},
-	).Parse(slurmdbdConfTpl)
+	slurmdbdConf, err := slurmconf.RenderSlurmdbdConf(conf)
 	if err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
-	if err := tpl.Execute(&buf, *conf); err != nil {
+	data := map[string]string{
+		"slurmdbd.conf": slurmdbdConf,
+	}
+
+	includes, err := confIncludes(client, wl, &wl.Spec.SlurmdbdConf, slurmdbdConfOwnedKeys)
+	if err != nil {
 		return err
 	}
 
+	maps.Copy(data, includes)
+
 	name := fmt.Sprintf("%s-slurmdbd", wl.Name)
 	cmSpec := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -66,9 +66,7 @@ func buildSlurmdbdConfigMap(client kubernetes.Interface, wl *v1s.Slik) error {
 				"app.kubernetes.io/managed-by": "slik",
 			},
 		},
-		Data: map[string]string{
-			"slurmdbd.conf": buf.String(),
-		},
+		Data: data,
 	}
 
 	log.Infof("configmap (slurmdbd.conf): %+v", cmSpec)
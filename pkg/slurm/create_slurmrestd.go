@@ -2,6 +2,7 @@ package slurm
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/vultr/slik/cmd/slik/config"
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
@@ -26,8 +27,8 @@ func buildSlurmrestdDeployment(client kubernetes.Interface, wl *v1s.Slik) error
 	slurmrestdCont := mkSlurmrestdContainer(wl)
 	annotations := configChecksumAnnotations(client, wl.Namespace,
 		fmt.Sprintf("%s-munged", wl.Name),
-		fmt.Sprintf("%s-slurm", wl.Name),
 	)
+	maps.Copy(annotations, slurmConfChecksumAnnotations(client, wl))
 
 	log.Infof("munged container: %+v", *mungeCont)
 	log.Infof("slurmrestd container: %+v", *slurmrestdCont)
@@ -104,6 +105,9 @@ func buildSlurmrestdDeployment(client kubernetes.Interface, wl *v1s.Slik) error
 		},
 	}
 
+	withSharedVolumes(&slurmrestdDep.Spec.Template.Spec, wl, v1s.ComponentSlurmrestd, "slurmrestd")
+	withClientConfServer(&slurmrestdDep.Spec.Template, wl, "slurmrestd")
+
 	log.Infof("slurmrestd deployment: %+v", slurmrestdDep)
 
 	if err := applyDeployment(client, slurmrestdDep); err != nil {
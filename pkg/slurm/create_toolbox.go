@@ -2,6 +2,7 @@ package slurm
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/vultr/slik/cmd/slik/config"
 	v1s "github.com/vultr/slik/pkg/api/types/v1"
@@ -25,8 +26,8 @@ func buildToolboxDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
 	slurmToolboxCont := mkSlurmToolboxContainer(wl)
 	annotations := configChecksumAnnotations(client, wl.Namespace,
 		fmt.Sprintf("%s-munged", wl.Name),
-		fmt.Sprintf("%s-slurm", wl.Name),
 	)
+	maps.Copy(annotations, slurmConfChecksumAnnotations(client, wl))
 
 	log.Infof("munged container: %+v", *mungeCont)
 	log.Infof("slurm-toolbox container: %+v", *slurmToolboxCont)
@@ -103,6 +104,10 @@ func buildToolboxDeployment(client kubernetes.Interface, wl *v1s.Slik) error {
 		},
 	}
 
+	withSharedVolumes(&slurmToolboxDep.Spec.Template.Spec, wl, v1s.ComponentToolbox, "slurm-toolbox")
+	withIdentity(client, &slurmToolboxDep.Spec.Template, wl, "slurm-toolbox")
+	withClientConfServer(&slurmToolboxDep.Spec.Template, wl, "slurm-toolbox")
+
 	log.Infof("slurm_toolbox deployment: %+v", slurmToolboxDep)
 
 	if err := applyDeployment(client, slurmToolboxDep); err != nil {
@@ -0,0 +1,27 @@
+package slurm
+
+import (
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultSlik sets the values slik falls back to for unset fields, so they are visible on the Slik. The
+// rendered resources are the same with or without the defaults.
+func DefaultSlik(wl *v1s.Slik) {
+	wl.Spec.Namespace = specNamespace(wl)
+
+	if wl.Spec.Login.Enabled {
+		wl.Spec.Login.Replicas = loginReplicas(wl)
+
+		if wl.Spec.Login.ServiceType == "" {
+			wl.Spec.Login.ServiceType = v1.ServiceTypeLoadBalancer
+		}
+	}
+
+	if wl.Spec.Slurmctld.HighAvailability {
+		wl.Spec.Slurmctld.StateStorageSize = stateStorageSize(wl)
+	}
+
+	wl.Spec.Drain.TimeoutSeconds = drainTimeout(wl)
+}
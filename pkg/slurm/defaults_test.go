@@ -0,0 +1,40 @@
+package slurm
+
+import (
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefaultSlik(t *testing.T) {
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "hpc"},
+		Spec: v1s.SlikSpec{
+			Login:     v1s.Login{Enabled: true},
+			Slurmctld: v1s.Slurmctld{HighAvailability: true},
+		},
+	}
+
+	DefaultSlik(wl)
+
+	spec := wl.Spec
+	if spec.Namespace != "hpc" || spec.Login.Replicas != 1 || spec.Login.ServiceType != corev1.ServiceTypeLoadBalancer ||
+		spec.Slurmctld.StateStorageSize != SlurmctldStateStorageSize || spec.Drain.TimeoutSeconds != DrainTimeoutSec {
+		t.Fatalf("unexpected defaults: %+v", spec)
+	}
+
+	// set values are kept, disabled components are left alone
+	wl = &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "hpc"},
+		Spec:       v1s.SlikSpec{Namespace: "hpc", Drain: v1s.Drain{TimeoutSeconds: 60}},
+	}
+
+	DefaultSlik(wl)
+
+	if wl.Spec.Drain.TimeoutSeconds != 60 || wl.Spec.Login.Replicas != 0 || wl.Spec.Slurmctld.StateStorageSize != "" {
+		t.Fatalf("unexpected defaults: %+v", wl.Spec)
+	}
+}
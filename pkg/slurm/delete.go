@@ -11,7 +11,7 @@ import (
 
 // SlurmDelete deletes slurm
 func SlurmDelete(client kubernetes.Interface, name, namespace string) error {
-	// slurmd deployments
+	// slurmd pods and per node deployments of earlier releases
 	nodes, err := GetAllNodes(client)
 	if err != nil {
 		return err
@@ -26,6 +26,32 @@ func SlurmDelete(client kubernetes.Interface, name, namespace string) error {
 		if err := DeploymentDelete(client, res, namespace); err != nil {
 			return err
 		}
+
+		if err := PodDisruptionBudgetDelete(client, res, namespace); err != nil {
+			return err
+		}
+
+		if err := PodDelete(client, res, namespace); err != nil {
+			return err
+		}
+	}
+
+	// compute pools
+	if err := client.AppsV1().StatefulSets(namespace).DeleteCollection(context.TODO(), v1.DeleteOptions{}, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s-slurmd,%s", name, ComputePoolLabel),
+	}); err != nil {
+		return err
+	}
+
+	// elastic slurmd pods
+	if err := client.CoreV1().Pods(namespace).DeleteCollection(context.TODO(), v1.DeleteOptions{}, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", ElasticNodeLabel, name),
+	}); err != nil {
+		return err
+	}
+
+	if err := ServiceDelete(client, fmt.Sprintf("%s-slurmd", name), namespace); err != nil {
+		return err
 	}
 
 	if err := DaemonSetDelete(client, fmt.Sprintf("%s-slurmabler", name), namespace); err != nil {
@@ -36,6 +62,18 @@ func SlurmDelete(client kubernetes.Interface, name, namespace string) error {
 		return err
 	}
 
+	if err := DeploymentDelete(client, fmt.Sprintf("%s-balloon", name), namespace); err != nil {
+		return err
+	}
+
+	if err := DeploymentDelete(client, fmt.Sprintf("%s-login", name), namespace); err != nil {
+		return err
+	}
+
+	if err := ServiceDelete(client, fmt.Sprintf("%s-login", name), namespace); err != nil {
+		return err
+	}
+
 	if err := DeploymentDelete(client, fmt.Sprintf("%s-slurmrestd", name), namespace); err != nil {
 		return err
 	}
@@ -68,6 +106,23 @@ func SlurmDelete(client kubernetes.Interface, name, namespace string) error {
 		return err
 	}
 
+	if err := StatefulSetDelete(client, fmt.Sprintf("%s-slurmctld", name), namespace); err != nil {
+		return err
+	}
+
+	if err := ServiceDelete(client, fmt.Sprintf("%s-slurmctld-hosts", name), namespace); err != nil {
+		return err
+	}
+
+	// the job state of the controllers does not outlive the cluster, unlike the mariadb accounting data
+	if err := PersistentVolumeClaimDelete(client, fmt.Sprintf("%s-slurmctld-state", name), namespace); err != nil {
+		return err
+	}
+
+	if err := PodDisruptionBudgetDelete(client, fmt.Sprintf("%s-slurmctld", name), namespace); err != nil {
+		return err
+	}
+
 	// config layer
 	mungedCM := fmt.Sprintf("%s-munged", name)
 	if err := ConfigMapDelete(client, mungedCM, namespace); err != nil {
@@ -92,6 +147,26 @@ func SlurmDelete(client kubernetes.Interface, name, namespace string) error {
 		return err
 	}
 
+	if err := SecretDelete(client, fmt.Sprintf("%s-login-hostkeys", name), namespace); err != nil {
+		return err
+	}
+
+	if err := SecretDelete(client, fmt.Sprintf("%s-sssd", name), namespace); err != nil {
+		return err
+	}
+
+	if err := ConfigMapDelete(client, fmt.Sprintf("%s-identity", name), namespace); err != nil {
+		return err
+	}
+
+	if err := SecretDelete(client, fmt.Sprintf("%s-power", name), namespace); err != nil {
+		return err
+	}
+
+	if err := ConfigMapDelete(client, fmt.Sprintf("%s-elastic", name), namespace); err != nil {
+		return err
+	}
+
 	switch namespace {
 	case "default", "kube-system":
 		// no-op, we don't touch default or kube-system namespaces
@@ -149,6 +224,21 @@ func ConfigMapDelete(client kubernetes.Interface, name, namespace string) error
 	return nil
 }
 
+// SecretDelete deletes secret if it exists
+func SecretDelete(client kubernetes.Interface, name, namespace string) error {
+	log := zap.L().Sugar()
+
+	if SecretExists(client, name, namespace) {
+		if err := client.CoreV1().Secrets(namespace).Delete(context.TODO(), name, v1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		log.Infof("secret %s deleted", name)
+	}
+
+	return nil
+}
+
 // ServiceDelete deletes deployment if it exists
 func ServiceDelete(client kubernetes.Interface, name, namespace string) error {
 	log := zap.L().Sugar()
@@ -193,3 +283,48 @@ func NamespaceDelete(client kubernetes.Interface, namespace string) error {
 
 	return nil
 }
+
+// PodDisruptionBudgetDelete deletes pdb if it exists
+func PodDisruptionBudgetDelete(client kubernetes.Interface, name, namespace string) error {
+	log := zap.L().Sugar()
+
+	if PodDisruptionBudgetExists(client, name, namespace) {
+		if err := client.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, v1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		log.Infof("pdb %s deleted", name)
+	}
+
+	return nil
+}
+
+// PersistentVolumeClaimDelete deletes pvc if it exists
+func PersistentVolumeClaimDelete(client kubernetes.Interface, name, namespace string) error {
+	log := zap.L().Sugar()
+
+	if PersistentVolumeClaimExists(client, name, namespace) {
+		if err := client.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, v1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		log.Infof("pvc %s deleted", name)
+	}
+
+	return nil
+}
+
+// PodDelete deletes pod if it exists
+func PodDelete(client kubernetes.Interface, name, namespace string) error {
+	log := zap.L().Sugar()
+
+	if PodExists(client, name, namespace) {
+		if err := client.CoreV1().Pods(namespace).Delete(context.TODO(), name, v1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		log.Infof("pod %s deleted", name)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -92,3 +93,17 @@ func TestSlurmDelete(t *testing.T) {
 		}
 	}
 }
+
+func TestSlurmDeleteSlurmctldState(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-slurmctld-state", Namespace: "default"},
+	})
+
+	if err := SlurmDelete(client, "test", "default"); err != nil {
+		t.Fatal(err)
+	}
+
+	if PersistentVolumeClaimExists(client, "test-slurmctld-state", "default") {
+		t.Fatal("expected the slurmctld state pvc to be deleted")
+	}
+}
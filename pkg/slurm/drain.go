@@ -0,0 +1,134 @@
+package slurm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DrainNodes drains the slurm nodes of cordoned, tainted or annotated kubernetes nodes,
+// once their jobs finished or the drain timed out the slurmd is removed. Progress is kept in wl.Status.Draining.
+// Failed slurm commands are logged and retried on the next reconcile, the spec is still applied while slurmctld
+// is down.
+func DrainNodes(client kubernetes.Interface, exec Executor, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	nodes, err := GetAllNodes(client)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(drainTimeout(wl)) * time.Second
+	draining := []v1s.DrainingNode{}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		slurmNode := fmt.Sprintf("%s-%s", wl.Name, node.Name)
+
+		reason, drain := drainRequested(node)
+		current := drainingNode(wl, node.Name)
+
+		switch {
+		case !drain && current != nil:
+			// uncordoned before the jobs finished, put the node back into service
+			log.Infof("resuming slurm node %s", slurmNode)
+
+			if _, err := slurmCommand(client, exec, wl, "scontrol", "update",
+				fmt.Sprintf("nodename=%s", slurmNode), "state=RESUME"); err != nil {
+				log.Errorf("resuming slurm node %s, retrying on the next reconcile: %s", slurmNode, err)
+
+				draining = append(draining, *current)
+			}
+		case !drain:
+			// no-op
+		case current == nil:
+			if !PodExists(client, slurmNode, wl.Namespace) && !DeploymentExists(client, slurmNode, wl.Namespace) {
+				continue
+			}
+
+			log.Infof("draining slurm node %s: %s", slurmNode, reason)
+
+			// the node is kept in slurm.conf while slurmctld is unreachable, its jobs are waited for once it is back
+			if _, err := slurmCommand(client, exec, wl, "scontrol", "update",
+				fmt.Sprintf("nodename=%s", slurmNode), "state=DRAIN", fmt.Sprintf("reason=%s", reason)); err != nil {
+				log.Errorf("draining slurm node %s: %s", slurmNode, err)
+			}
+
+			draining = append(draining, v1s.DrainingNode{
+				Node:      node.Name,
+				Reason:    reason,
+				StartTime: metav1.Now(),
+			})
+		default:
+			jobs, err := slurmCommand(client, exec, wl, "squeue", "--noheader",
+				fmt.Sprintf("--nodelist=%s", slurmNode), "--states=RUNNING,COMPLETING", "--format=%i")
+			if err != nil {
+				log.Errorf("listing jobs of slurm node %s: %s", slurmNode, err)
+
+				draining = append(draining, *current)
+
+				continue
+			}
+
+			timedOut := time.Since(current.StartTime.Time) > timeout
+			if strings.TrimSpace(jobs) != "" && !timedOut {
+				log.Infof("slurm node %s is draining, running jobs: %s", slurmNode, strings.Fields(jobs))
+
+				draining = append(draining, *current)
+
+				continue
+			}
+
+			if timedOut {
+				log.Warnf("slurm node %s drain timed out after %s, removing with running jobs", slurmNode, timeout)
+			}
+
+			if err := PodDelete(client, slurmNode, wl.Namespace); err != nil {
+				return err
+			}
+
+			// per node deployment and service of earlier releases
+			if err := DeploymentDelete(client, slurmNode, wl.Namespace); err != nil {
+				return err
+			}
+
+			if err := ServiceDelete(client, slurmNode, wl.Namespace); err != nil {
+				return err
+			}
+
+			if err := PodDisruptionBudgetDelete(client, slurmNode, wl.Namespace); err != nil {
+				return err
+			}
+
+			log.Infof("slurm node %s drained and removed", slurmNode)
+		}
+	}
+
+	wl.Status.Draining = draining
+
+	return nil
+}
+
+func drainTimeout(wl *v1s.Slik) int32 {
+	if wl.Spec.Drain.TimeoutSeconds > 0 {
+		return wl.Spec.Drain.TimeoutSeconds
+	}
+
+	return DrainTimeoutSec
+}
+
+func drainingNode(wl *v1s.Slik, node string) *v1s.DrainingNode {
+	for i := range wl.Status.Draining {
+		if wl.Status.Draining[i].Node == node {
+			return &wl.Status.Draining[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package slurm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeExecutor records commands and answers with the output configured for the program
+type fakeExecutor struct {
+	commands []string
+	outputs  map[string]string
+	// errs fails the commands of a program
+	errs map[string]error
+}
+
+// Exec returns the output of the whole command, or of its program
+func (e *fakeExecutor) Exec(namespace, pod, container string, command []string) (string, error) {
+	e.commands = append(e.commands, strings.Join(command, " "))
+
+	if err := e.errs[command[0]]; err != nil {
+		return "", err
+	}
+
+	if out, ok := e.outputs[strings.Join(command, " ")]; ok {
+		return out, nil
+	}
+
+	return e.outputs[command[0]], nil
+}
+
+func drainFixture(node *corev1.Node) *fake.Clientset {
+	return fake.NewSimpleClientset(
+		node,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-worker", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-worker", Namespace: "default"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-worker", Namespace: "default"}},
+	)
+}
+
+func TestDrainNodes(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker",
+			Annotations: map[string]string{DrainAnnotation: "disk replacement"},
+		},
+	}
+
+	client := drainFixture(node)
+	exec := &fakeExecutor{outputs: map[string]string{"squeue": "42\n"}}
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	// drain is issued and the node stays in slurm
+	if err := DrainNodes(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 1 || exec.commands[0] != "scontrol update nodename=test-worker state=DRAIN reason=disk replacement" {
+		t.Fatalf("unexpected commands: %v", exec.commands)
+	}
+
+	if len(wl.Status.Draining) != 1 || wl.Status.Draining[0].Reason != "disk replacement" {
+		t.Fatalf("expected worker to be draining, got %+v", wl.Status.Draining)
+	}
+
+	// jobs are still running
+	if err := DrainNodes(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wl.Status.Draining) != 1 || !DeploymentExists(client, "test-worker", "default") {
+		t.Fatal("expected worker to keep draining while jobs run")
+	}
+
+	// jobs finished, slurmd is removed
+	exec.outputs["squeue"] = ""
+	if err := DrainNodes(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wl.Status.Draining) != 0 || PodExists(client, "test-worker", "default") ||
+		DeploymentExists(client, "test-worker", "default") || ServiceExists(client, "test-worker", "default") {
+		t.Fatal("expected drained worker to be removed")
+	}
+}
+
+func TestDrainNodesSlurmctldDown(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+	}
+
+	client := drainFixture(node)
+	exec := &fakeExecutor{errs: map[string]error{
+		"scontrol": errors.New("slurm_update error: Unable to contact slurm controller"),
+		"squeue":   errors.New("slurm_load_jobs error: Unable to contact slurm controller"),
+	}}
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	// the failed drain keeps the node in slurm, and the jobs can not be listed
+	for range 2 {
+		if err := DrainNodes(client, exec, wl); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(wl.Status.Draining) != 1 || !DeploymentExists(client, "test-worker", "default") {
+			t.Fatalf("expected worker to keep draining, got %+v", wl.Status.Draining)
+		}
+	}
+}
+
+func TestDrainNodesTimeout(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+	}
+
+	client := drainFixture(node)
+	exec := &fakeExecutor{outputs: map[string]string{"squeue": "42\n"}}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Drain: v1s.Drain{TimeoutSeconds: 60}},
+		Status: v1s.SlikStatus{
+			Draining: []v1s.DrainingNode{
+				{
+					Node:      "worker",
+					Reason:    DrainDefaultReason,
+					StartTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+				},
+			},
+		},
+	}
+
+	if err := DrainNodes(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wl.Status.Draining) != 0 || DeploymentExists(client, "test-worker", "default") {
+		t.Fatal("expected timed out drain to remove the worker")
+	}
+}
+
+func TestDrainNodesResume(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}
+
+	client := drainFixture(node)
+	exec := &fakeExecutor{}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status: v1s.SlikStatus{
+			Draining: []v1s.DrainingNode{
+				{Node: "worker", Reason: DrainDefaultReason, StartTime: metav1.Now()},
+			},
+		},
+	}
+
+	if err := DrainNodes(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 1 || exec.commands[0] != "scontrol update nodename=test-worker state=RESUME" {
+		t.Fatalf("unexpected commands: %v", exec.commands)
+	}
+
+	if len(wl.Status.Draining) != 0 || !DeploymentExists(client, "test-worker", "default") {
+		t.Fatal("expected uncordoned worker to stay in slurm")
+	}
+}
@@ -0,0 +1,269 @@
+package slurm
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	clientv1 "github.com/vultr/slik/pkg/clientset/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// DryRun returns true if the Slik is annotated to compute its pending changes instead of applying them
+func DryRun(wl *v1s.Slik) bool {
+	return wl.Annotations[DryRunAnnotation] == "true"
+}
+
+// PendingChanges runs CreateSlurm and BuildSlurmdNodeSet against a fake clientset seeded with the live objects
+// of the cluster namespace and returns the objects that would change. Fields only set on the live object are
+// defaulted by the api server and are not reported.
+func PendingChanges(client kubernetes.Interface, nodeSets clientv1.SlurmNodeSetInterface, wl *v1s.Slik) ([]v1s.PendingChange, error) {
+	live, err := renderObjects(client, wl.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := GetAllNodes(client)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := slices.Clone(live)
+	for i := range nodes.Items {
+		fixtures = append(fixtures, &nodes.Items[i])
+	}
+
+	memory := &renderNodeSets{items: map[string]*v1s.SlurmNodeSet{}}
+
+	nodeSet, err := nodeSets.Get(fmt.Sprintf("%s-slurmd", wl.Name), metav1.GetOptions{})
+	switch {
+	case err == nil:
+		nodeSet.TypeMeta = metav1.TypeMeta{APIVersion: v1s.SchemeGroupVersion.String(), Kind: "SlurmNodeSet"}
+		memory.items[nodeSet.Name] = nodeSet.DeepCopy()
+		live = append(live, nodeSet)
+	case !errors.IsNotFound(err):
+		return nil, err
+	}
+
+	desired, err := renderCluster(fake.NewSimpleClientset(fixtures...), memory, wl, nodes.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffObjects(live, desired), nil
+}
+
+// diffObjects returns the changes from the live to the desired objects, in the order of desired
+func diffObjects(live, desired []runtime.Object) []v1s.PendingChange {
+	before := map[string]runtime.Object{}
+	for _, obj := range live {
+		before[renderKey(obj)] = obj
+	}
+
+	after := map[string]bool{}
+	changes := []v1s.PendingChange{}
+
+	for _, obj := range desired {
+		key := renderKey(obj)
+		after[key] = true
+
+		old, ok := before[key]
+		switch {
+		case !ok:
+			changes = append(changes, pendingChange(obj, v1s.PendingActionCreate))
+		case changed(old, obj):
+			change := pendingChange(obj, v1s.PendingActionUpdate)
+			change.Restart, change.Checksums = podTemplateChanges(old, obj)
+			changes = append(changes, change)
+		}
+	}
+
+	for _, obj := range live {
+		if !after[renderKey(obj)] {
+			changes = append(changes, pendingChange(obj, v1s.PendingActionDelete))
+		}
+	}
+
+	return changes
+}
+
+// replacedMaps are the string maps slik replaces as a whole, a key only set in live is removed on apply
+var replacedMaps = [][]string{
+	{"metadata", "labels"},
+	{"metadata", "annotations"},
+	{"spec", "nodeSelector"},
+	{"spec", "template", "metadata", "labels"},
+	{"spec", "template", "metadata", "annotations"},
+	{"spec", "template", "spec", "nodeSelector"},
+}
+
+// serverAnnotations are set by the kubernetes controllers again after slik replaced the annotations
+var serverAnnotations = []string{
+	"deployment.kubernetes.io/revision",
+	"deprecated.daemonset.template.generation",
+}
+
+// changed returns true if desired differs from live, including keys removed from a ConfigMap, a Secret or
+// one of the replacedMaps
+func changed(live, desired runtime.Object) bool {
+	if !subset(appliedFields(desired), appliedFields(live)) || removedKeys(appliedFields(desired), appliedFields(live)) {
+		return true
+	}
+
+	switch d := desired.(type) {
+	case *v1.ConfigMap:
+		l, ok := live.(*v1.ConfigMap)
+
+		return !ok || len(l.Data) != len(d.Data) || len(l.BinaryData) != len(d.BinaryData)
+	case *v1.Secret:
+		l, ok := live.(*v1.Secret)
+
+		return !ok || len(l.Data) != len(d.Data)
+	}
+
+	return false
+}
+
+func pendingChange(obj runtime.Object, action string) v1s.PendingChange {
+	name := ""
+	if o, ok := obj.(metav1.Object); ok {
+		name = o.GetName()
+	}
+
+	return v1s.PendingChange{
+		Kind:   obj.GetObjectKind().GroupVersionKind().Kind,
+		Name:   name,
+		Action: action,
+	}
+}
+
+// podTemplateChanges returns true if the pods of a workload would be replaced, with the checksum annotations
+// that changed
+func podTemplateChanges(live, desired runtime.Object) (bool, []string) {
+	oldTpl, newTpl := podTemplate(live), podTemplate(desired)
+	if oldTpl == nil || newTpl == nil {
+		return false, nil
+	}
+
+	if subset(appliedFields(newTpl), appliedFields(oldTpl)) && !removedKeys(appliedFields(newTpl), appliedFields(oldTpl)) {
+		return false, nil
+	}
+
+	checksums := []string{}
+	for key, value := range newTpl.Annotations {
+		if strings.HasPrefix(key, "slik.vultr.com/checksum-") && oldTpl.Annotations[key] != value {
+			checksums = append(checksums, key)
+		}
+	}
+
+	slices.Sort(checksums)
+
+	return true, checksums
+}
+
+func podTemplate(obj runtime.Object) *v1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template
+	case *v1s.SlurmNodeSet:
+		return &o.Spec.Template
+	}
+
+	return nil
+}
+
+// appliedFields returns the fields of obj applied by slik as unstructured json, without status and server
+// managed metadata
+func appliedFields(obj any) map[string]any {
+	m := map[string]any{}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return m
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m
+	}
+
+	delete(m, "status")
+
+	if meta, ok := m["metadata"].(map[string]any); ok {
+		m["metadata"] = map[string]any{"labels": meta["labels"], "annotations": meta["annotations"]}
+	}
+
+	return m
+}
+
+// subset returns true if every field set in desired has the same value in live
+func subset(desired, live any) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+
+		for key, value := range d {
+			if !subset(value, l[key]) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			return len(d) == 0 && live == nil
+		}
+
+		for i := range d {
+			if !subset(d[i], l[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return desired == live
+	}
+}
+
+// removedKeys returns true if one of the replacedMaps of live has a key desired does not set
+func removedKeys(desired, live map[string]any) bool {
+	for _, path := range replacedMaps {
+		d, l := fieldMap(desired, path), fieldMap(live, path)
+
+		for key := range l {
+			if _, ok := d[key]; !ok && !slices.Contains(serverAnnotations, key) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fieldMap returns the map at path of unstructured json, nil if it is not set
+func fieldMap(m map[string]any, path []string) map[string]any {
+	for _, key := range path {
+		m, _ = m[key].(map[string]any)
+	}
+
+	return m
+}
@@ -0,0 +1,127 @@
+package slurm
+
+import (
+	"slices"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPendingChanges(t *testing.T) {
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false))
+	nodeSets := &renderNodeSets{items: map[string]*v1s.SlurmNodeSet{}}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Namespace: "default"},
+	}
+
+	if err := CreateSlurm(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := BuildSlurmdNodeSet(client, nodeSets, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := PendingChanges(client, nodeSets, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes for the applied spec, got %+v", changes)
+	}
+
+	before, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wl.Spec.SlurmConf.Extra = []v1s.ConfParameter{{Key: "MaxJobCount", Value: "50000"}}
+	wl.Spec.Login.Enabled = true
+
+	changes, err = PendingChanges(client, nodeSets, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	find := func(kind, name string) *v1s.PendingChange {
+		i := slices.IndexFunc(changes, func(c v1s.PendingChange) bool { return c.Kind == kind && c.Name == name })
+		if i < 0 {
+			t.Fatalf("expected a change of %s %s, got %+v", kind, name, changes)
+		}
+
+		return &changes[i]
+	}
+
+	if c := find("ConfigMap", "test-slurm"); c.Action != v1s.PendingActionUpdate || c.Restart {
+		t.Fatalf("expected slurm.conf updated, got %+v", c)
+	}
+
+	for _, c := range []*v1s.PendingChange{find("Deployment", "test-slurmctld"), find("SlurmNodeSet", "test-slurmd")} {
+		if c.Action != v1s.PendingActionUpdate || !c.Restart || !slices.Contains(c.Checksums, "slik.vultr.com/checksum-test-slurm") {
+			t.Fatalf("expected a restart for slurm.conf, got %+v", c)
+		}
+	}
+
+	if c := find("Deployment", "test-login"); c.Action != v1s.PendingActionCreate {
+		t.Fatalf("expected login created, got %+v", c)
+	}
+
+	// nothing is applied
+	after, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.Data["slurm.conf"] != before.Data["slurm.conf"] || DeploymentExists(client, "test-login", "default") {
+		t.Fatal("expected the live cluster unchanged")
+	}
+
+	wl.Spec.Login.Enabled = false
+	wl.Spec.SlurmConf.Extra = nil
+
+	changes, err = PendingChanges(client, nodeSets, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes once reverted, got %+v", changes)
+	}
+}
+
+func TestChangedRemovedKeys(t *testing.T) {
+	desired := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-toolbox", Labels: map[string]string{"app": "test-toolbox"}},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{NodeSelector: map[string]string{"pool": "cpu"}},
+			},
+		},
+	}
+
+	live := desired.DeepCopy()
+	live.Annotations = map[string]string{"deployment.kubernetes.io/revision": "3"}
+
+	if changed(live, desired) {
+		t.Fatal("expected annotations of the deployment controller to be ignored")
+	}
+
+	live.Labels["team"] = "hpc"
+	if !changed(live, desired) {
+		t.Fatal("expected a removed label to be a change")
+	}
+
+	live = desired.DeepCopy()
+	live.Spec.Template.Spec.NodeSelector["zone"] = "ewr"
+
+	if restart, _ := podTemplateChanges(live, desired); !changed(live, desired) || !restart {
+		t.Fatal("expected a removed node selector to restart the pods")
+	}
+}
@@ -0,0 +1,335 @@
+package slurm
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+	"github.com/vultr/slik/pkg/util/rnd"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func elasticEnabled(wl *v1s.Slik) bool {
+	return wl.Spec.Elastic.Nodes > 0
+}
+
+// elasticNodeName is the pod, host and slurm node name of an elastic node
+func elasticNodeName(name string, i int) string {
+	return fmt.Sprintf("%s-elastic-%d", name, i)
+}
+
+// isElasticNode returns true if the kubernetes node belongs to an elastic pool, static slurmd pods are not run there
+func isElasticNode(node *v1.Node) bool {
+	_, ok := node.GetLabels()[ElasticNodeLabel]
+
+	return ok
+}
+
+// elasticSlurmdNodes returns the CLOUD nodes for slurm.conf, named without the cluster prefix like static nodes
+func elasticSlurmdNodes(wl *v1s.Slik) []slurmconf.Node {
+	nodes := []slurmconf.Node{}
+	for i := 0; i < int(wl.Spec.Elastic.Nodes); i++ {
+		nodes = append(nodes, slurmconf.Node{
+			NodeName:       fmt.Sprintf("elastic-%d", i),
+			CPUs:           int(wl.Spec.Elastic.CPUs),
+			ThreadsPerCore: int(wl.Spec.Elastic.ThreadsPerCore),
+			RealMemory:     int(wl.Spec.Elastic.RealMemory),
+		})
+	}
+
+	return nodes
+}
+
+// buildElasticPowerSecret creates the token and url slurmctld uses to call the power api, the token is kept
+func buildElasticPowerSecret(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	name := fmt.Sprintf("%s-power", wl.Name)
+	token := []byte(rnd.RandomString(ElasticPowerTokenLength))
+
+	if secret, err := GetSecret(client, name, wl.Namespace); err == nil && len(secret.Data["token"]) > 0 {
+		token = secret.Data["token"]
+	}
+
+	secretSpec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"token": token,
+			"url":   fmt.Appendf(nil, "%s/v1/%s/%s", config.GetPowerAPIURL(), wl.Namespace, wl.Name),
+		},
+	}
+
+	log.Infof("secret (power): %s", name)
+
+	return applySecret(client, secretSpec)
+}
+
+// withPowerSecret mounts the power api secret read by the resume and suspend programs
+func withPowerSecret(tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) {
+	if !elasticEnabled(wl) {
+		return
+	}
+
+	tpl.Spec.Volumes = append(tpl.Spec.Volumes, v1.Volume{
+		Name: "power",
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: fmt.Sprintf("%s-power", wl.Name),
+			},
+		},
+	})
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].VolumeMounts = append(tpl.Spec.Containers[i].VolumeMounts, v1.VolumeMount{
+			Name:      "power",
+			MountPath: ElasticPowerSecretMount,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// CheckPowerToken returns true if token matches the power secret of the cluster
+func CheckPowerToken(client kubernetes.Interface, namespace, name, token string) bool {
+	secret, err := GetSecret(client, fmt.Sprintf("%s-power", name), namespace)
+	if err != nil || len(secret.Data["token"]) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(secret.Data["token"], []byte(token)) == 1
+}
+
+// SetElasticPower records elastic nodes powered up or down by slurm, the slurmd pods follow on the next reconcile
+func SetElasticPower(client kubernetes.Interface, namespace, name string, nodes []string, resume bool) error {
+	log := zap.L().Sugar()
+
+	for _, node := range nodes {
+		index, ok := strings.CutPrefix(node, fmt.Sprintf("%s-elastic-", name))
+		if _, err := strconv.Atoi(index); !ok || err != nil {
+			return fmt.Errorf("%w: %s", ErrNotElasticNode, node)
+		}
+	}
+
+	cmName := fmt.Sprintf("%s-elastic", name)
+
+	for {
+		cm, err := GetConfigMap(client, cmName, namespace)
+		create := errors.IsNotFound(err)
+		if create {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cmName,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "slik",
+					},
+				},
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+
+		for _, node := range nodes {
+			if resume {
+				log.Infof("resuming elastic node %s", node)
+
+				cm.Data[node] = time.Now().UTC().Format(time.RFC3339)
+			} else {
+				log.Infof("suspending elastic node %s", node)
+
+				delete(cm.Data, node)
+			}
+		}
+
+		if create {
+			_, err = client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		} else {
+			_, err = client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		}
+
+		if errors.IsConflict(err) || errors.IsAlreadyExists(err) {
+			time.Sleep(time.Duration(ConflictRetryIntervalSec) * time.Second)
+
+			continue
+		}
+
+		return err
+	}
+}
+
+// ReconcileElasticNodes runs a slurmd pod for every elastic node slurm powered up and removes the pods of
+// suspended nodes. Pending pods let the cluster autoscaler add kubernetes nodes to the elastic pool.
+func ReconcileElasticNodes(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	resumed := map[string]bool{}
+	if elasticEnabled(wl) {
+		if err := buildElasticPowerSecret(client, wl); err != nil {
+			return err
+		}
+
+		cm, err := GetConfigMap(client, fmt.Sprintf("%s-elastic", wl.Name), wl.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		for i := 0; i < int(wl.Spec.Elastic.Nodes); i++ {
+			if cm != nil {
+				if _, ok := cm.Data[elasticNodeName(wl.Name, i)]; ok {
+					resumed[elasticNodeName(wl.Name, i)] = true
+				}
+			}
+		}
+	}
+
+	// pods of an older template are replaced, like the slurmd StatefulSet rolls its pods
+	var tpl *v1.PodTemplateSpec
+	revision := ""
+	if len(resumed) > 0 {
+		var err error
+
+		tpl, err = mkSlurmdPodTemplate(client, wl)
+		if err != nil {
+			return err
+		}
+
+		revision, err = templateRevision(tpl)
+		if err != nil {
+			return err
+		}
+	}
+
+	pods, err := client.CoreV1().Pods(wl.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", ElasticNodeLabel, wl.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	running := map[string]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if !resumed[pod.Name] || pod.Status.Phase == v1.PodFailed || pod.Annotations[ElasticRevisionAnnotation] != revision {
+			log.Infof("deleting elastic slurmd pod %s", pod.Name)
+
+			if err := PodDelete(client, pod.Name, pod.Namespace); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		running[pod.Name] = true
+	}
+
+	if len(resumed) == len(running) {
+		return nil
+	}
+
+	for _, node := range slices.Sorted(maps.Keys(resumed)) {
+		if running[node] {
+			continue
+		}
+
+		log.Infof("creating elastic slurmd pod %s", node)
+
+		if _, err := client.CoreV1().Pods(wl.Namespace).Create(context.TODO(),
+			mkElasticPod(tpl, wl, node, revision), metav1.CreateOptions{}); ignoreAlreadyExists(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mkElasticPod returns the slurmd pod of an elastic node, owned by the Slik so it is removed with the cluster
+func mkElasticPod(tpl *v1.PodTemplateSpec, wl *v1s.Slik, node, revision string) *v1.Pod {
+	tpl = tpl.DeepCopy()
+	controller := true
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        node,
+			Namespace:   wl.Namespace,
+			Labels:      tpl.Labels,
+			Annotations: tpl.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1s.SchemeGroupVersion.String(),
+					Kind:       "Slik",
+					Name:       wl.Name,
+					UID:        wl.UID,
+					Controller: &controller,
+				},
+			},
+		},
+		Spec: tpl.Spec,
+	}
+
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+
+	pod.Labels[ElasticNodeLabel] = wl.Name
+	pod.Annotations[ElasticRevisionAnnotation] = revision
+	pod.Spec.Hostname = node
+	pod.Spec.Subdomain = fmt.Sprintf("%s-slurmd", wl.Name)
+	pod.Spec.NodeSelector = map[string]string{
+		ElasticNodeLabel: wl.Name,
+	}
+
+	// one elastic node per kubernetes node, the autoscaler adds a node for every pending pod
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &v1.Affinity{}
+	}
+
+	pod.Spec.Affinity.PodAntiAffinity = &v1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+			{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						ElasticNodeLabel: wl.Name,
+					},
+				},
+				TopologyKey: "kubernetes.io/hostname",
+			},
+		},
+	}
+
+	return pod
+}
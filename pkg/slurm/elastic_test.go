@@ -0,0 +1,167 @@
+package slurm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func elasticCluster() *v1s.Slik {
+	return &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Elastic: v1s.Elastic{
+				Nodes:          2,
+				CPUs:           4,
+				RealMemory:     8192,
+				ThreadsPerCore: 1,
+				SuspendTime:    300,
+				ResumeTimeout:  600,
+			},
+		},
+	}
+}
+
+func TestElasticSlurmConf(t *testing.T) {
+	elastic := slurmLabeledNode("pool-1", false)
+	elastic.Labels[ElasticNodeLabel] = "test"
+
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false), elastic)
+
+	if err := buildSlurmconfConfigMap(client, elasticCluster()); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := cm.Data["slurm.conf"]
+	for _, want := range []string{
+		"ResumeProgram=/usr/local/bin/slik-resume",
+		"SuspendTime=300",
+		"NodeName=test-a NodeAddr=test-a.test-slurmd CPUs=2",
+		"NodeName=test-elastic-1 NodeAddr=test-elastic-1.test-slurmd State=CLOUD CPUs=4 RealMemory=8192",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", want, conf)
+		}
+	}
+
+	if strings.Contains(conf, "test-pool-1") {
+		t.Fatalf("expected elastic kubernetes node not to be a static node:\n%s", conf)
+	}
+}
+
+func TestReconcileElasticNodes(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	wl := elasticCluster()
+
+	if err := SetElasticPower(client, "default", "test", []string{"test-elastic-1"}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReconcileElasticNodes(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err := GetPod(client, "test-elastic-1", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pod.Spec.Hostname != "test-elastic-1" || pod.Spec.NodeSelector[ElasticNodeLabel] != "test" ||
+		PodExists(client, "test-elastic-0", "default") {
+		t.Fatalf("unexpected elastic pod: %+v", pod)
+	}
+
+	secret, err := GetSecret(client, "test-power", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !CheckPowerToken(client, "default", "test", string(secret.Data["token"])) ||
+		CheckPowerToken(client, "default", "test", "wrong") {
+		t.Fatal("expected only the generated token to be accepted")
+	}
+
+	// suspended by slurm, the pod is removed
+	if err := SetElasticPower(client, "default", "test", []string{"test-elastic-1"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReconcileElasticNodes(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if PodExists(client, "test-elastic-1", "default") {
+		t.Fatal("expected suspended elastic pod to be deleted")
+	}
+}
+
+func TestReconcileElasticNodesRollsPods(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	wl := elasticCluster()
+	wl.UID = "uid"
+
+	if err := SetElasticPower(client, "default", "test", []string{"test-elastic-0"}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReconcileElasticNodes(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err := GetPod(client, "test-elastic-0", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pod.OwnerReferences) != 1 || pod.OwnerReferences[0].Kind != "Slik" || pod.OwnerReferences[0].UID != "uid" {
+		t.Fatalf("expected the elastic pod to be owned by the Slik: %+v", pod.OwnerReferences)
+	}
+
+	revision := pod.Annotations[ElasticRevisionAnnotation]
+
+	// a munge key change is part of the slurmd template, the pod is replaced
+	if _, err := client.CoreV1().ConfigMaps("default").Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-munged", Namespace: "default"},
+		Data:       map[string]string{"munge.key": "key"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReconcileElasticNodes(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err = GetPod(client, "test-elastic-0", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pod.Annotations[ElasticRevisionAnnotation] == revision {
+		t.Fatalf("expected the elastic pod to be recreated from the new template, revision %s", revision)
+	}
+}
+
+func TestSetElasticPowerRejectsOtherNodes(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-elastic", Namespace: "default"},
+	})
+
+	for _, node := range []string{"test-a", "other-elastic-0", "test-elastic-x"} {
+		err := SetElasticPower(client, "default", "test", []string{node}, true)
+		if !errors.Is(err, ErrNotElasticNode) {
+			t.Fatalf("expected %s to be rejected, got %v", node, err)
+		}
+	}
+}
@@ -1,6 +1,54 @@
 package slurm
 
-import apierrors "k8s.io/apimachinery/pkg/api/errors"
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// ErrSharedVolumeNotShareable claim mounted by multiple pods without ReadWriteMany
+	ErrSharedVolumeNotShareable = errors.New("claim mounted by multiple pods must be ReadWriteMany")
+
+	// ErrSecretKeyNotFound referenced secret key does not exist
+	ErrSecretKeyNotFound = errors.New("secret key not found")
+
+	// ErrSlurmctldNotRunning no running slurmctld pod to run slurm commands in
+	ErrSlurmctldNotRunning = errors.New("no running slurmctld pod")
+
+	// ErrConfigMapKeyNotFound referenced configmap key does not exist
+	ErrConfigMapKeyNotFound = errors.New("configmap key not found")
+
+	// ErrOwnedConfKey override of a configuration parameter rendered by slik
+	ErrOwnedConfKey = errors.New("parameter is managed by slik")
+
+	// ErrInvalidConfParameter override that is not a single Key=Value line
+	ErrInvalidConfParameter = errors.New("invalid configuration parameter")
+
+	// ErrNotElasticNode power saving call for a node that is not an elastic node of the cluster
+	ErrNotElasticNode = errors.New("not an elastic node")
+
+	// ErrNodesNotLabeled render fixtures with slurmable nodes missing the slurmabler labels
+	ErrNodesNotLabeled = errors.New("nodes are missing slurmabler labels")
+
+	// ErrUnsupportedFixture render fixture of a kind that is not seeded
+	ErrUnsupportedFixture = errors.New("unsupported fixture kind")
+
+	// ErrInvalidStorageSize storage size that is not a quantity, too small or smaller than the claim it resizes
+	ErrInvalidStorageSize = errors.New("invalid storage size")
+
+	// ErrImmutableField update of a field that can not change once the cluster is created
+	ErrImmutableField = errors.New("field is immutable")
+
+	// ErrNameTooLong Slik name that overflows the names of the resources derived from it
+	ErrNameTooLong = errors.New("name is too long")
+
+	// ErrConflictingComponents component enabled without the component it depends on
+	ErrConflictingComponents = errors.New("conflicting components")
+
+	// ErrInvalidSpec spec field that can not be deployed
+	ErrInvalidSpec = errors.New("invalid spec")
+)
 
 func ignoreAlreadyExists(err error) error {
 	if apierrors.IsAlreadyExists(err) {
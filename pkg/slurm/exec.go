@@ -0,0 +1,83 @@
+package slurm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Executor runs a command in a container and returns its stdout
+type Executor interface {
+	Exec(namespace, pod, container string, command []string) (string, error)
+}
+
+type podExecutor struct {
+	client kubernetes.Interface
+	config *rest.Config
+}
+
+// NewPodExecutor returns an Executor using the pods/exec subresource
+func NewPodExecutor(client kubernetes.Interface, config *rest.Config) Executor {
+	return &podExecutor{
+		client: client,
+		config: config,
+	}
+}
+
+func (e *podExecutor) Exec(namespace, pod, container string, command []string) (string, error) {
+	req := e.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(context.TODO(), remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		return "", fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// slurmCommand runs a slurm client command, e.g. scontrol, in a running slurmctld pod
+func slurmCommand(client kubernetes.Interface, exec Executor, wl *v1s.Slik, command ...string) (string, error) {
+	pods, err := client.CoreV1().Pods(wl.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s-slurmctld", wl.Name),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		return exec.Exec(wl.Namespace, pods.Items[i].Name, "slurmctld", command)
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrSlurmctldNotRunning, wl.Name)
+}
@@ -0,0 +1,92 @@
+package slurm
+
+import (
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func gpuNode(name string) *corev1.Node {
+	node := slurmLabeledNode(name, false)
+	node.Labels[nodeLabelGPUs] = "2"
+	node.Labels[nodeLabelGPUType] = "a100"
+
+	return node
+}
+
+func TestGresSlurmConf(t *testing.T) {
+	// the device plugin only exposes 1 of the 2 devices
+	plugin := gpuNode("b")
+	plugin.Status.Allocatable = corev1.ResourceList{corev1.ResourceName(GPUResource): resource.MustParse("1")}
+
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false), gpuNode("g"), plugin)
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := cm.Data["slurm.conf"]
+	for _, want := range []string{
+		"GresTypes=gpu\n",
+		"NodeName=test-a NodeAddr=test-a.test-slurmd CPUs=2 RealMemory=1024 ThreadsPerCore=1\n",
+		"NodeName=test-b NodeAddr=test-b.test-slurmd CPUs=2 RealMemory=1024 ThreadsPerCore=1 Gres=gpu:a100:1\n",
+		"NodeName=test-g NodeAddr=test-g.test-slurmd CPUs=2 RealMemory=1024 ThreadsPerCore=1 Gres=gpu:a100:2\n",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", want, conf)
+		}
+	}
+
+	want := "\nNodeName=test-b Name=gpu Type=a100 Count=1\n" +
+		"NodeName=test-g Name=gpu Type=a100 File=/dev/nvidia[0-1]\n"
+	if cm.Data["gres.conf"] != want {
+		t.Fatalf("unexpected gres.conf:\n%s", cm.Data["gres.conf"])
+	}
+}
+
+func TestNoGresWithoutGPUs(t *testing.T) {
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false))
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cm.Data["gres.conf"]; ok || strings.Contains(cm.Data["slurm.conf"], "Gres") {
+		t.Fatalf("expected no gres without gpus: %+v", cm.Data)
+	}
+}
+
+func TestNodeSetPodRequestsGPUs(t *testing.T) {
+	node := gpuNode("g")
+	node.Status.Allocatable = corev1.ResourceList{corev1.ResourceName(GPUResource): resource.MustParse("2")}
+
+	pod := mkNodeSetPod(testNodeSet("slurmd:1"), node, "rev")
+
+	limit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(GPUResource)]
+	if limit.Value() != 2 {
+		t.Fatalf("expected 2 gpus requested by slurmd, got %+v", pod.Spec.Containers[0].Resources)
+	}
+
+	pod = mkNodeSetPod(testNodeSet("slurmd:1"), slurmLabeledNode("a", false), "rev")
+	if len(pod.Spec.Containers[0].Resources.Limits) != 0 {
+		t.Fatalf("expected no gpus requested, got %+v", pod.Spec.Containers[0].Resources)
+	}
+}
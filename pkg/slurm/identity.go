@@ -0,0 +1,291 @@
+package slurm
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"text/template"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SSSDConf configuration for generation of sssd.conf
+type SSSDConf struct {
+	URI          string
+	BaseDN       string
+	BindDN       string
+	BindPassword string
+	CACert       bool
+}
+
+// NewSSSDConf builds SSSDConf for templating out sssd.conf, the bind password is read from its secret
+func NewSSSDConf(client kubernetes.Interface, wl *v1s.Slik) (*SSSDConf, error) {
+	ldap := &wl.Spec.Identity.LDAP
+
+	conf := SSSDConf{
+		URI:    ldap.URI,
+		BaseDN: ldap.BaseDN,
+		BindDN: ldap.BindDN,
+		CACert: ldap.CACertSecretRef != nil,
+	}
+
+	if ldap.BindPasswordSecretRef != nil {
+		secret, err := GetSecret(client, ldap.BindPasswordSecretRef.Name, wl.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		password, ok := secret.Data[ldap.BindPasswordSecretRef.Key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s/%s", ErrSecretKeyNotFound, ldap.BindPasswordSecretRef.Name, ldap.BindPasswordSecretRef.Key)
+		}
+
+		conf.BindPassword = string(password)
+	}
+
+	return &conf, nil
+}
+
+// buildIdentityConfig creates the sssd.conf secret and nsswitch.conf configmap for ldap identity
+func buildIdentityConfig(client kubernetes.Interface, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	if wl.Spec.Identity.Mode != v1s.IdentityModeLDAP {
+		return nil
+	}
+
+	conf, err := NewSSSDConf(client, wl)
+	if err != nil {
+		return err
+	}
+
+	// text/template, the bind password must not be escaped
+	tpl, err := template.New("sssd_conf").Parse(sssdConfTpl)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, *conf); err != nil {
+		return err
+	}
+
+	secretSpec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-sssd", wl.Name),
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"sssd.conf": buf.Bytes(),
+		},
+	}
+
+	log.Infof("secret (sssd.conf): %s", secretSpec.Name)
+
+	if err := applySecret(client, secretSpec); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-identity", wl.Name)
+	cmSpec := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: wl.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "slik",
+			},
+		},
+		Data: map[string]string{
+			"nsswitch.conf": nsswitchSSSConf,
+		},
+	}
+
+	log.Infof("configmap (identity): %+v", cmSpec)
+
+	if err := applyConfigMap(client, cmSpec); err != nil {
+		return err
+	}
+
+	WaitForConfigMap(client, name, wl.Namespace)
+
+	return nil
+}
+
+// withIdentity injects the configured identity source into a pod template, resolving users in the named container
+func withIdentity(client kubernetes.Interface, tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) {
+	var annotations map[string]string
+
+	switch wl.Spec.Identity.Mode {
+	case v1s.IdentityModeLDAP:
+		annotations = withSSSD(client, tpl, wl, container)
+	case v1s.IdentityModeLocal:
+		annotations = withLocalIdentity(client, tpl, wl, container)
+	default:
+		return
+	}
+
+	if tpl.Annotations == nil {
+		tpl.Annotations = map[string]string{}
+	}
+
+	maps.Copy(tpl.Annotations, annotations)
+}
+
+func withSSSD(client kubernetes.Interface, tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) map[string]string {
+	var sssdMode int32 = 0o600
+
+	tpl.Spec.InitContainers = append(tpl.Spec.InitContainers, *mkSSSDContainer(wl))
+	tpl.Spec.Volumes = append(tpl.Spec.Volumes,
+		v1.Volume{
+			Name: "sss-pipes",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+		v1.Volume{
+			Name: "sssd-conf",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName:  fmt.Sprintf("%s-sssd", wl.Name),
+					DefaultMode: &sssdMode,
+				},
+			},
+		},
+		v1.Volume{
+			Name: "identity",
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: fmt.Sprintf("%s-identity", wl.Name),
+					},
+				},
+			},
+		},
+	)
+
+	if ref := wl.Spec.Identity.LDAP.CACertSecretRef; ref != nil {
+		tpl.Spec.Volumes = append(tpl.Spec.Volumes, v1.Volume{
+			Name: "sssd-ca",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: ref.Name,
+					Items: []v1.KeyToPath{
+						{Key: ref.Key, Path: "ca.crt"},
+					},
+				},
+			},
+		})
+	}
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].VolumeMounts = append(tpl.Spec.Containers[i].VolumeMounts,
+			v1.VolumeMount{
+				Name:      "sss-pipes",
+				MountPath: "/var/lib/sss/pipes",
+			},
+			v1.VolumeMount{
+				Name:      "identity",
+				MountPath: "/etc/nsswitch.conf",
+				SubPath:   "nsswitch.conf",
+				ReadOnly:  true,
+			},
+		)
+	}
+
+	annotations := configChecksumAnnotations(client, wl.Namespace, fmt.Sprintf("%s-identity", wl.Name))
+	maps.Copy(annotations, secretChecksumAnnotations(client, wl.Namespace, fmt.Sprintf("%s-sssd", wl.Name)))
+
+	return annotations
+}
+
+func withLocalIdentity(client kubernetes.Interface, tpl *v1.PodTemplateSpec, wl *v1s.Slik, container string) map[string]string {
+	tpl.Spec.Volumes = append(tpl.Spec.Volumes, v1.Volume{
+		Name: "identity",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: wl.Spec.Identity.Local.ConfigMap,
+				},
+			},
+		},
+	})
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].VolumeMounts = append(tpl.Spec.Containers[i].VolumeMounts,
+			v1.VolumeMount{
+				Name:      "identity",
+				MountPath: "/etc/passwd",
+				SubPath:   "passwd",
+				ReadOnly:  true,
+			},
+			v1.VolumeMount{
+				Name:      "identity",
+				MountPath: "/etc/group",
+				SubPath:   "group",
+				ReadOnly:  true,
+			},
+		)
+	}
+
+	// subPath mounts are not refreshed, roll the pods when the accounts change
+	return configChecksumAnnotations(client, wl.Namespace, wl.Spec.Identity.Local.ConfigMap)
+}
+
+func mkSSSDContainer(wl *v1s.Slik) *v1.Container {
+	c := v1.Container{
+		Name:  "sssd",
+		Image: config.GetSlurmSSSDImage(),
+	}
+
+	c.VolumeMounts = []v1.VolumeMount{
+		{
+			Name:      "sssd-conf",
+			MountPath: "/etc/sssd/sssd.conf",
+			SubPath:   "sssd.conf",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "sss-pipes",
+			MountPath: "/var/lib/sss/pipes",
+		},
+	}
+
+	if wl.Spec.Identity.LDAP.CACertSecretRef != nil {
+		c.VolumeMounts = append(c.VolumeMounts, v1.VolumeMount{
+			Name:      "sssd-ca",
+			MountPath: "/etc/sssd/certs",
+			ReadOnly:  true,
+		})
+	}
+
+	c.Env = []v1.EnvVar{
+		{
+			Name:  "X_VULTR_SLURM_ID",
+			Value: wl.Name,
+		},
+	}
+
+	// sidecar, users must resolve before slurm starts
+	always := v1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+
+	return &c
+}
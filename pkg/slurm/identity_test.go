@@ -0,0 +1,130 @@
+package slurm
+
+import (
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildIdentityConfigLDAP(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte(`p&ss<w>rd"`)},
+	})
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Identity: v1s.Identity{
+				Mode: v1s.IdentityModeLDAP,
+				LDAP: v1s.LDAPIdentity{
+					URI:    "ldaps://ldap.example.com",
+					BaseDN: "dc=example,dc=com",
+					BindDN: "cn=slurm,dc=example,dc=com",
+					BindPasswordSecretRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "ldap-bind"},
+						Key:                  "password",
+					},
+				},
+			},
+		},
+	}
+
+	if err := buildIdentityConfig(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := GetSecret(client, "test-sssd", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := string(secret.Data["sssd.conf"])
+	if !strings.Contains(conf, `ldap_default_authtok = p&ss<w>rd"`) {
+		t.Fatalf("expected unescaped bind password in sssd.conf, got:\n%s", conf)
+	}
+
+	if !ConfigMapExists(client, "test-identity", "default") {
+		t.Fatal("expected identity configmap")
+	}
+
+	tpl := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "slurmd"}},
+		},
+	}
+
+	withIdentity(client, tpl, wl, "slurmd")
+
+	if len(tpl.Spec.InitContainers) != 1 || tpl.Spec.InitContainers[0].Name != "sssd" {
+		t.Fatalf("expected sssd sidecar, got %+v", tpl.Spec.InitContainers)
+	}
+
+	if len(tpl.Spec.Containers[0].VolumeMounts) != 2 || tpl.Annotations["slik.vultr.com/checksum-test-sssd"] == "" {
+		t.Fatalf("unexpected slurmd pod template: %+v", tpl)
+	}
+}
+
+func TestBuildIdentityConfigMissingKey(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: "default"},
+	})
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Identity: v1s.Identity{
+				Mode: v1s.IdentityModeLDAP,
+				LDAP: v1s.LDAPIdentity{
+					URI:    "ldap://ldap",
+					BaseDN: "dc=example",
+					BindDN: "cn=slurm",
+					BindPasswordSecretRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "ldap-bind"},
+						Key:                  "password",
+					},
+				},
+			},
+		},
+	}
+
+	if err := buildIdentityConfig(client, wl); err == nil {
+		t.Fatal("expected missing bind password key to fail")
+	}
+}
+
+func TestWithLocalIdentity(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Identity: v1s.Identity{
+				Mode:  v1s.IdentityModeLocal,
+				Local: v1s.LocalIdentity{ConfigMap: "accounts"},
+			},
+		},
+	}
+
+	tpl := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "login"}, {Name: "other"}},
+		},
+	}
+
+	withIdentity(client, tpl, wl, "login")
+
+	mounts := tpl.Spec.Containers[0].VolumeMounts
+	if len(mounts) != 2 || mounts[0].MountPath != "/etc/passwd" || mounts[1].MountPath != "/etc/group" {
+		t.Fatalf("expected passwd and group mounts, got %+v", mounts)
+	}
+
+	if len(tpl.Spec.Containers[1].VolumeMounts) != 0 || len(tpl.Spec.InitContainers) != 0 {
+		t.Fatal("expected only the login container to be changed")
+	}
+}
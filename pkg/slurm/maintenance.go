@@ -0,0 +1,126 @@
+package slurm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Maintenance drains every slurm node while spec.maintenance is set and resumes them once it is unset, the
+// partitions are set DOWN in slurm.conf. Nodes joining during maintenance are drained on the next reconcile,
+// nodes drained for another reason are left as they are. Progress is kept in wl.Status.Maintenance.
+func Maintenance(client kubernetes.Interface, exec Executor, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	if !wl.Spec.Maintenance && wl.Status.Maintenance == "" {
+		return nil
+	}
+
+	drained, err := drainedNodes(client, exec, wl)
+	if err != nil {
+		return err
+	}
+
+	if !wl.Spec.Maintenance {
+		nodes := []string{}
+		for node, reason := range drained {
+			if reason == MaintenanceReason {
+				nodes = append(nodes, node)
+			}
+		}
+
+		slices.Sort(nodes)
+
+		log.Infof("slurm cluster %s leaving maintenance, resuming nodes: %v", wl.Name, nodes)
+
+		if len(nodes) > 0 {
+			if _, err := slurmCommand(client, exec, wl, "scontrol", "update",
+				fmt.Sprintf("nodename=%s", strings.Join(nodes, ",")), "state=RESUME"); err != nil {
+				return err
+			}
+		}
+
+		wl.Status.Maintenance = ""
+
+		return nil
+	}
+
+	nodes, err := maintenanceNodes(client, exec, wl)
+	if err != nil {
+		return err
+	}
+
+	// a node drained for a rolling update is taken over, so it is not resumed after the update
+	nodes = slices.DeleteFunc(nodes, func(node string) bool {
+		reason, ok := drained[node]
+
+		return ok && reason != NodeSetUpdateReason
+	})
+
+	if len(nodes) > 0 {
+		if _, err := slurmCommand(client, exec, wl, "scontrol", "update",
+			fmt.Sprintf("nodename=%s", strings.Join(nodes, ",")), "state=DRAIN",
+			fmt.Sprintf("reason=%s", MaintenanceReason)); err != nil {
+			return err
+		}
+	}
+
+	jobs, err := slurmCommand(client, exec, wl, "squeue", "--noheader", "--states=RUNNING,COMPLETING", "--format=%i")
+	if err != nil {
+		return err
+	}
+
+	wl.Status.Maintenance = v1s.MaintenanceDrained
+	if strings.TrimSpace(jobs) != "" {
+		wl.Status.Maintenance = v1s.MaintenanceDraining
+	}
+
+	log.Infof("slurm cluster %s in maintenance: %s, running jobs: %v", wl.Name, wl.Status.Maintenance, strings.Fields(jobs))
+
+	return nil
+}
+
+// maintenanceNodes returns the slurm nodes drained for maintenance, the nodes drained for their kubernetes
+// node are left to DrainNodes
+func maintenanceNodes(client kubernetes.Interface, exec Executor, wl *v1s.Slik) ([]string, error) {
+	out, err := slurmCommand(client, exec, wl, "sinfo", "--noheader", "--Node", "--format=%N")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []string{}
+	for _, node := range strings.Fields(out) {
+		drained := slices.ContainsFunc(wl.Status.Draining, func(d v1s.DrainingNode) bool {
+			return fmt.Sprintf("%s-%s", wl.Name, d.Node) == node
+		})
+
+		if !drained && !slices.Contains(nodes, node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// drainedNodes returns the reason of every drained or draining slurm node
+func drainedNodes(client kubernetes.Interface, exec Executor, wl *v1s.Slik) (map[string]string, error) {
+	out, err := slurmCommand(client, exec, wl, "sinfo", "--noheader", "--Node", "--format=%N|%E", "--states=drain")
+	if err != nil {
+		return nil, err
+	}
+
+	drained := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		node, reason, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if ok {
+			drained[node] = strings.TrimSpace(reason)
+		}
+	}
+
+	return drained, nil
+}
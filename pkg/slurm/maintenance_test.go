@@ -0,0 +1,107 @@
+package slurm
+
+import (
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// drainedCommand is the sinfo command of drainedNodes
+const drainedCommand = "sinfo --noheader --Node --format=%N|%E --states=drain"
+
+func TestMaintenance(t *testing.T) {
+	client := drainFixture(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}})
+	exec := &fakeExecutor{outputs: map[string]string{
+		"sinfo":        "test-worker\ntest-gpu\ntest-gpu\ntest-cordoned\ntest-admin\ntest-updating\n",
+		drainedCommand: "test-admin|bad dimm\ntest-updating|" + NodeSetUpdateReason + "\n",
+		"squeue":       "42\n",
+	}}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Maintenance: true},
+		Status: v1s.SlikStatus{
+			Draining: []v1s.DrainingNode{{Node: "cordoned", Reason: "disk replacement"}},
+		},
+	}
+
+	// every node but the cordoned one and the one drained by an admin is drained, jobs are still running
+	if err := Maintenance(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if exec.commands[2] != "scontrol update nodename=test-worker,test-gpu,test-updating state=DRAIN reason="+MaintenanceReason {
+		t.Fatalf("unexpected commands: %v", exec.commands)
+	}
+
+	if wl.Status.Maintenance != v1s.MaintenanceDraining {
+		t.Fatalf("expected %s, got %q", v1s.MaintenanceDraining, wl.Status.Maintenance)
+	}
+
+	// jobs finished
+	exec.outputs["squeue"] = ""
+	exec.outputs[drainedCommand] = "test-admin|bad dimm\ntest-worker|slik maintenance\n" +
+		"test-gpu|slik maintenance\ntest-updating|slik maintenance\n"
+	exec.commands = nil
+	if err := Maintenance(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if wl.Status.Maintenance != v1s.MaintenanceDrained || len(exec.commands) != 3 {
+		t.Fatalf("expected %s without draining again, got %q %v", v1s.MaintenanceDrained, wl.Status.Maintenance, exec.commands)
+	}
+
+	// maintenance is over, the nodes drained by someone else stay drained
+	exec.commands = nil
+	wl.Spec.Maintenance = false
+	if err := Maintenance(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 2 || exec.commands[1] != "scontrol update nodename=test-gpu,test-updating,test-worker state=RESUME" {
+		t.Fatalf("unexpected commands: %v", exec.commands)
+	}
+
+	if wl.Status.Maintenance != "" {
+		t.Fatalf("expected maintenance to be cleared, got %q", wl.Status.Maintenance)
+	}
+
+	// nothing to do outside of maintenance
+	exec.commands = nil
+	if err := Maintenance(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 0 {
+		t.Fatalf("unexpected commands: %v", exec.commands)
+	}
+
+	// no node left drained for maintenance
+	wl.Status.Maintenance = v1s.MaintenanceDrained
+	exec.outputs[drainedCommand] = "test-admin|bad dimm\n"
+	if err := Maintenance(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 1 || wl.Status.Maintenance != "" {
+		t.Fatalf("expected maintenance to be cleared without resuming, got %q %v", wl.Status.Maintenance, exec.commands)
+	}
+}
+
+func TestMaintenanceSlurmConf(t *testing.T) {
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1s.SlikSpec{Maintenance: true},
+	}
+
+	conf, err := NewSlurmConf(drainFixture(slurmLabeledNode("a", false)), wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !conf.Maintenance {
+		t.Fatal("expected spec.maintenance in the slurm.conf model")
+	}
+}
@@ -2,8 +2,13 @@ package slurm
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -11,9 +16,14 @@ import (
 )
 
 const (
-	nodeLabelCPUs           = "slik.vultr.com/cpus"
-	nodeLabelRealMemory     = "slik.vultr.com/real_memory"
-	nodeLabelThreadsPerCore = "slik.vultr.com/threads_per_core"
+	nodeLabelCPUs            = "slik.vultr.com/cpus"
+	nodeLabelRealMemory      = "slik.vultr.com/real_memory"
+	nodeLabelThreadsPerCore  = "slik.vultr.com/threads_per_core"
+	nodeLabelBoards          = "slik.vultr.com/boards"
+	nodeLabelSocketsPerBoard = "slik.vultr.com/sockets_per_board"
+	nodeLabelCoresPerSocket  = "slik.vultr.com/cores_per_socket"
+	nodeLabelGPUs            = "slik.vultr.com/gpus"
+	nodeLabelGPUType         = "slik.vultr.com/gpu_type"
 )
 
 func waitForSlurmableNodes(client kubernetes.Interface) error {
@@ -53,7 +63,13 @@ func pendingSlurmableLabels(nodes []corev1.Node) []string {
 	return pending
 }
 
-func slurmNodes(client kubernetes.Interface) ([]corev1.Node, error) {
+// slurmNodes returns the nodes running slurmd, nodes that are still draining are kept until their jobs finish
+func slurmNodes(client kubernetes.Interface, wl *v1s.Slik) ([]corev1.Node, error) {
+	// compute pools replace the slurmd per kubernetes node
+	if computePoolsEnabled(wl) {
+		return []corev1.Node{}, nil
+	}
+
 	nodes, err := GetAllNodes(client)
 	if err != nil {
 		return nil, err
@@ -61,7 +77,11 @@ func slurmNodes(client kubernetes.Interface) ([]corev1.Node, error) {
 
 	result := []corev1.Node{}
 	for i := range nodes.Items {
-		if isSlurmableNode(&nodes.Items[i]) && hasSlurmLabels(&nodes.Items[i]) {
+		if !hasSlurmLabels(&nodes.Items[i]) || isElasticNode(&nodes.Items[i]) {
+			continue
+		}
+
+		if isSlurmableNode(&nodes.Items[i]) || drainingNode(wl, nodes.Items[i].Name) != nil {
 			result = append(result, nodes.Items[i])
 		}
 	}
@@ -70,18 +90,33 @@ func slurmNodes(client kubernetes.Interface) ([]corev1.Node, error) {
 }
 
 func isSlurmableNode(node *corev1.Node) bool {
+	_, drain := drainRequested(node)
+
+	return !drain
+}
+
+// drainRequested returns the drain reason if the node is cordoned, tainted or annotated for drain
+func drainRequested(node *corev1.Node) (string, bool) {
+	if reason, ok := node.GetAnnotations()[DrainAnnotation]; ok {
+		if reason == "" {
+			reason = fmt.Sprintf("%s annotation", DrainAnnotation)
+		}
+
+		return reason, true
+	}
+
 	if node.Spec.Unschedulable {
-		return false
+		return DrainDefaultReason, true
 	}
 
 	for i := range node.Spec.Taints {
 		switch node.Spec.Taints[i].Effect {
 		case corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute:
-			return false
+			return fmt.Sprintf("kubernetes node tainted %s", node.Spec.Taints[i].Key), true
 		}
 	}
 
-	return true
+	return "", false
 }
 
 func hasSlurmLabels(node *corev1.Node) bool {
@@ -96,3 +131,74 @@ func hasSlurmLabels(node *corev1.Node) bool {
 
 	return hasCPUs && hasMemory && hasThreadsPerCore
 }
+
+// nodeGPUs returns the GPUs slurmd gets on the node, the allocatable of the device plugin or else the
+// devices found by slurmabler, and their type. It returns false when the device plugin allocates fewer GPUs
+// than slurmabler found, the device files slurmd gets are then not known.
+func nodeGPUs(node *corev1.Node) (int, string, bool) {
+	labels := node.GetLabels()
+
+	devices, _ := strconv.Atoi(labels[nodeLabelGPUs])
+	if q, ok := node.Status.Allocatable[corev1.ResourceName(GPUResource)]; ok && q.Value() > 0 {
+		return int(q.Value()), labels[nodeLabelGPUType], int(q.Value()) >= devices
+	}
+
+	return devices, labels[nodeLabelGPUType], true
+}
+
+// nodeTopology returns boards, sockets per board and cores per socket of the node, all 0 if the labels are
+// missing or do not add up to the cpus, slurm then only gets CPUs and ThreadsPerCore
+func nodeTopology(node *corev1.Node, cpus, threadsPerCore int) (int, int, int) {
+	log := zap.L().Sugar()
+
+	labels := node.GetLabels()
+
+	boards, err1 := strconv.Atoi(labels[nodeLabelBoards])
+	sockets, err2 := strconv.Atoi(labels[nodeLabelSocketsPerBoard])
+	cores, err3 := strconv.Atoi(labels[nodeLabelCoresPerSocket])
+
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, 0
+	}
+
+	if boards <= 0 || sockets <= 0 || cores <= 0 || boards*sockets*cores*threadsPerCore != cpus {
+		log.Warnf("node %s topology %d boards, %d sockets per board, %d cores per socket and %d threads per core does not match %d cpus",
+			node.Name, boards, sockets, cores, threadsPerCore, cpus)
+
+		return 0, 0, 0
+	}
+
+	return boards, sockets, cores
+}
+
+// nodeFeatures returns the values of the spec.nodeFeatures labels of the node as slurm features, sorted
+func nodeFeatures(wl *v1s.Slik, node *corev1.Node) []string {
+	var features []string
+	for _, key := range wl.Spec.NodeFeatures {
+		value, ok := node.GetLabels()[key]
+		if !ok || value == "" {
+			continue
+		}
+
+		feature := slurmName(value)
+
+		if !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+
+	slices.Sort(features)
+
+	return features
+}
+
+// slurmName replaces characters slurm uses in constraints and node lists of a label value with _
+func slurmName(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '.' || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, value)
+}
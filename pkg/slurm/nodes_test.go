@@ -1,8 +1,13 @@
 package slurm
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -71,7 +76,7 @@ func TestSlurmNodesReturnsOnlyEligibleLabeledNodes(t *testing.T) {
 		},
 	}})
 
-	nodes, err := slurmNodes(client)
+	nodes, err := slurmNodes(client, &v1s.Slik{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,3 +85,103 @@ func TestSlurmNodesReturnsOnlyEligibleLabeledNodes(t *testing.T) {
 		t.Fatalf("expected only eligible labeled node, got %v", nodes)
 	}
 }
+
+func topologyNode(name, cpus, boards, sockets, cores, threads string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				nodeLabelCPUs:            cpus,
+				nodeLabelRealMemory:      "1024",
+				nodeLabelBoards:          boards,
+				nodeLabelSocketsPerBoard: sockets,
+				nodeLabelCoresPerSocket:  cores,
+				nodeLabelThreadsPerCore:  threads,
+			},
+		},
+	}
+}
+
+func TestNewSlurmConfHeterogeneousTopology(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		topologyNode("dual", "64", "1", "2", "16", "2"),
+		topologyNode("single", "8", "1", "1", "8", "1"),
+		// does not add up, only cpus and threads are used
+		topologyNode("broken", "6", "1", "2", "2", "2"),
+	)
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	conf, err := NewSlurmConf(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]slurmconf.Node{
+		"dual":   {NodeName: "dual", CPUs: 64, RealMemory: 1024, Boards: 1, SocketsPerBoard: 2, CoresPerSocket: 16, ThreadsPerCore: 2},
+		"single": {NodeName: "single", CPUs: 8, RealMemory: 1024, Boards: 1, SocketsPerBoard: 1, CoresPerSocket: 8, ThreadsPerCore: 1},
+		"broken": {NodeName: "broken", CPUs: 6, RealMemory: 1024, ThreadsPerCore: 2},
+	}
+
+	if len(conf.SlurmdNodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %+v", len(want), conf.SlurmdNodes)
+	}
+
+	for _, node := range conf.SlurmdNodes {
+		if !reflect.DeepEqual(node, want[node.NodeName]) {
+			t.Fatalf("expected %+v, got %+v", want[node.NodeName], node)
+		}
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"NodeName=test-dual NodeAddr=test-dual.test-slurmd CPUs=64 RealMemory=1024 Boards=1 SocketsPerBoard=2 CoresPerSocket=16 ThreadsPerCore=2\n",
+		"NodeName=test-single NodeAddr=test-single.test-slurmd CPUs=8 RealMemory=1024 Boards=1 SocketsPerBoard=1 CoresPerSocket=8 ThreadsPerCore=1\n",
+		"NodeName=test-broken NodeAddr=test-broken.test-slurmd CPUs=6 RealMemory=1024 ThreadsPerCore=2\n",
+	} {
+		if !strings.Contains(cm.Data["slurm.conf"], line) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", line, cm.Data["slurm.conf"])
+		}
+	}
+}
+
+func TestNodeFeatures(t *testing.T) {
+	node := slurmLabeledNode("a", false)
+	node.Labels["topology.kubernetes.io/zone"] = "ewr-1"
+	node.Labels["node.kubernetes.io/instance-type"] = "vcg-a100-12c-120g"
+	node.Labels["example.com/fabric"] = "ib hdr"
+
+	client := fake.NewSimpleClientset(node, slurmLabeledNode("b", false))
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			NodeFeatures: []string{"topology.kubernetes.io/zone", "node.kubernetes.io/instance-type", "example.com/fabric"},
+		},
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := cm.Data["slurm.conf"]
+	for _, line := range []string{
+		"NodeName=test-a NodeAddr=test-a.test-slurmd CPUs=2 RealMemory=1024 ThreadsPerCore=1 Features=ewr-1,ib_hdr,vcg-a100-12c-120g\n",
+		"NodeName=test-b NodeAddr=test-b.test-slurmd CPUs=2 RealMemory=1024 ThreadsPerCore=1\n",
+	} {
+		if !strings.Contains(conf, line) {
+			t.Fatalf("expected %q in slurm.conf:\n%s", line, conf)
+		}
+	}
+}
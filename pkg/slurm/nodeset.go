@@ -0,0 +1,438 @@
+package slurm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ReconcileNodeSet runs one slurmd pod per selected node and rolls out template changes,
+// draining the slurm nodes of a batch before their pods are replaced. Progress is kept in ns.Status.
+func ReconcileNodeSet(client kubernetes.Interface, exec Executor, ns *v1s.SlurmNodeSet) error {
+	log := zap.L().Sugar()
+
+	revision, err := templateRevision(&ns.Spec.Template)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := GetAllNodes(client)
+	if err != nil {
+		return err
+	}
+
+	pods, err := nodeSetPods(client, ns)
+	if err != nil {
+		return err
+	}
+
+	selected := map[string]*v1.Node{}
+	for i := range nodes.Items {
+		if nodeSetSelects(ns, &nodes.Items[i], pods[nodes.Items[i].Name] != nil) {
+			selected[nodes.Items[i].Name] = &nodes.Items[i]
+		}
+	}
+
+	// nodes that left the set, e.g. deleted or relabeled
+	for node, pod := range pods {
+		if _, ok := selected[node]; ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := PodDelete(client, pod.Name, pod.Namespace); err != nil {
+			return err
+		}
+	}
+
+	for _, node := range slices.Sorted(maps.Keys(selected)) {
+		pod := pods[node]
+
+		switch {
+		case pod == nil:
+			// per node deployment and service of earlier releases
+			if err := DeploymentDelete(client, nodeSetPodName(ns, node), ns.Namespace); err != nil {
+				return err
+			}
+
+			if err := ServiceDelete(client, nodeSetPodName(ns, node), ns.Namespace); err != nil {
+				return err
+			}
+
+			log.Infof("creating slurmd pod %s", nodeSetPodName(ns, node))
+
+			if _, err := client.CoreV1().Pods(ns.Namespace).Create(context.TODO(),
+				mkNodeSetPod(ns, selected[node], revision), metav1.CreateOptions{}); err != nil {
+				return err
+			}
+		case pod.DeletionTimestamp != nil:
+			// no-op
+		case pod.Status.Phase == v1.PodFailed || pod.Status.Phase == v1.PodSucceeded:
+			if err := PodDelete(client, pod.Name, pod.Namespace); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := rollingUpdate(client, exec, ns, selected, pods, revision); err != nil {
+		return err
+	}
+
+	pods, err = nodeSetPods(client, ns)
+	if err != nil {
+		return err
+	}
+
+	ns.Status.Revision = revision
+	ns.Status.Nodes = int32(len(selected))
+	ns.Status.ReadyNodes = 0
+	ns.Status.UpdatedNodes = 0
+
+	for node := range selected {
+		pod := pods[node]
+		if pod == nil || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if podReady(pod) {
+			ns.Status.ReadyNodes++
+		}
+
+		if pod.Annotations[NodeSetRevisionAnnotation] == revision {
+			ns.Status.UpdatedNodes++
+		}
+	}
+
+	return nil
+}
+
+// rollingUpdate replaces outdated pods in node name order, at most maxUnavailable nodes, and maxUnavailablePerPartition
+// nodes of a slurm partition, are drained or restarting at once
+func rollingUpdate(client kubernetes.Interface, exec Executor, ns *v1s.SlurmNodeSet,
+	selected map[string]*v1.Node, pods map[string]*v1.Pod, revision string,
+) error {
+	log := zap.L().Sugar()
+
+	maxUnavailable := int(max(ns.Spec.UpdateStrategy.MaxUnavailable, 1))
+	maxPerPartition := int(ns.Spec.UpdateStrategy.MaxUnavailablePerPartition)
+	updating := []string{}
+
+	// drain reasons of the slurm nodes, read once a node is drained or resumed
+	var drained map[string]string
+	var err error
+
+	readDrained := func() error {
+		if drained == nil {
+			drained, err = drainedNodes(client, exec, clusterOf(ns))
+		}
+
+		return err
+	}
+
+	// keep track of drained nodes even if a later command fails
+	defer func() { ns.Status.Updating = updating }()
+
+	for _, node := range ns.Status.Updating {
+		if _, ok := selected[node]; !ok {
+			continue
+		}
+
+		slurmNode := nodeSetPodName(ns, node)
+		pod := pods[node]
+
+		switch {
+		case pod == nil || pod.DeletionTimestamp != nil:
+			// replacement is created once the old pod is gone
+			updating = append(updating, node)
+		case pod.Annotations[NodeSetRevisionAnnotation] == revision:
+			if !podReady(pod) {
+				updating = append(updating, node)
+
+				continue
+			}
+
+			// a cordoned node stays drained
+			if !isSlurmableNode(selected[node]) {
+				continue
+			}
+
+			if err := readDrained(); err != nil {
+				return err
+			}
+
+			// nodes drained before the update, or since for spec.maintenance, keep their drain
+			if reason := drained[slurmNode]; reason != "" && reason != NodeSetUpdateReason {
+				log.Infof("slurmd %s updated, staying drained: %s", slurmNode, reason)
+
+				continue
+			}
+
+			log.Infof("slurmd %s updated, resuming", slurmNode)
+
+			if _, err := slurmCommand(client, exec, clusterOf(ns), "scontrol", "update",
+				fmt.Sprintf("nodename=%s", slurmNode), "state=RESUME"); err != nil {
+				return err
+			}
+		default:
+			updating = append(updating, node)
+
+			jobs, err := slurmCommand(client, exec, clusterOf(ns), "squeue", "--noheader",
+				fmt.Sprintf("--nodelist=%s", slurmNode), "--states=RUNNING,COMPLETING", "--format=%i")
+			if err != nil {
+				return err
+			}
+
+			if strings.TrimSpace(jobs) != "" {
+				log.Infof("slurmd %s waiting for jobs before update: %s", slurmNode, strings.Fields(jobs))
+
+				continue
+			}
+
+			log.Infof("slurmd %s drained, replacing pod", slurmNode)
+
+			if err := PodDelete(client, pod.Name, pod.Namespace); err != nil {
+				return err
+			}
+		}
+	}
+
+	unavailableNodes := slices.Clone(updating)
+	for node := range selected {
+		pod := pods[node]
+		if pod != nil && !podReady(pod) && !slices.Contains(updating, node) {
+			unavailableNodes = append(unavailableNodes, node)
+		}
+	}
+
+	unavailable := len(unavailableNodes)
+
+	// unavailable nodes by slurm partition, read once a node is drained
+	var partitions map[string][]string
+	unavailableInPartition := map[string]int{}
+
+	for _, node := range slices.Sorted(maps.Keys(selected)) {
+		if unavailable >= maxUnavailable {
+			break
+		}
+
+		pod := pods[node]
+		if pod == nil || pod.DeletionTimestamp != nil ||
+			pod.Annotations[NodeSetRevisionAnnotation] == revision || slices.Contains(updating, node) {
+			continue
+		}
+
+		slurmNode := nodeSetPodName(ns, node)
+
+		if maxPerPartition > 0 {
+			if partitions == nil {
+				if partitions, err = nodePartitions(client, exec, clusterOf(ns)); err != nil {
+					return err
+				}
+
+				for _, unavailableNode := range unavailableNodes {
+					for _, partition := range partitions[nodeSetPodName(ns, unavailableNode)] {
+						unavailableInPartition[partition]++
+					}
+				}
+			}
+
+			if slices.ContainsFunc(partitions[slurmNode], func(partition string) bool {
+				return unavailableInPartition[partition] >= maxPerPartition
+			}) {
+				continue
+			}
+
+			for _, partition := range partitions[slurmNode] {
+				unavailableInPartition[partition]++
+			}
+		}
+
+		if err := readDrained(); err != nil {
+			return err
+		}
+
+		// a drain set by an admin is kept, the node is not resumed after the update
+		if reason := drained[slurmNode]; reason != "" && reason != NodeSetUpdateReason {
+			log.Infof("slurmd %s already drained for update to revision %s: %s", slurmNode, revision, reason)
+		} else {
+			log.Infof("draining slurmd %s for update to revision %s", slurmNode, revision)
+
+			if _, err := slurmCommand(client, exec, clusterOf(ns), "scontrol", "update",
+				fmt.Sprintf("nodename=%s", slurmNode), "state=DRAIN", fmt.Sprintf("reason=%s", NodeSetUpdateReason)); err != nil {
+				return err
+			}
+		}
+
+		updating = append(updating, node)
+		unavailable++
+	}
+
+	return nil
+}
+
+// nodePartitions returns the slurm partitions of every slurm node
+func nodePartitions(client kubernetes.Interface, exec Executor, wl *v1s.Slik) (map[string][]string, error) {
+	out, err := slurmCommand(client, exec, wl, "sinfo", "--noheader", "--Node", "--format=%N|%R")
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := map[string][]string{}
+	for _, line := range strings.Split(out, "\n") {
+		node, partition, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if ok && !slices.Contains(partitions[node], partition) {
+			partitions[node] = append(partitions[node], partition)
+		}
+	}
+
+	return partitions, nil
+}
+
+// nodeSetSelects returns true if the node should run a slurmd of the set, a cordoned node keeps
+// its existing pod until it has been drained
+func nodeSetSelects(ns *v1s.SlurmNodeSet, node *v1.Node, hasPod bool) bool {
+	if !hasSlurmLabels(node) || isElasticNode(node) {
+		return false
+	}
+
+	for key, value := range ns.Spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+
+	return hasPod || isSlurmableNode(node)
+}
+
+// nodeSetPods returns the pods of the set by kubernetes node
+func nodeSetPods(client kubernetes.Interface, ns *v1s.SlurmNodeSet) (map[string]*v1.Pod, error) {
+	list, err := client.CoreV1().Pods(ns.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", NodeSetLabel, ns.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := map[string]*v1.Pod{}
+	for i := range list.Items {
+		pods[list.Items[i].Labels["host"]] = &list.Items[i]
+	}
+
+	return pods, nil
+}
+
+func mkNodeSetPod(ns *v1s.SlurmNodeSet, node *v1.Node, revision string) *v1.Pod {
+	tpl := ns.Spec.Template.DeepCopy()
+	name := nodeSetPodName(ns, node.Name)
+	controller := true
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   ns.Namespace,
+			Labels:      tpl.Labels,
+			Annotations: tpl.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1s.SchemeGroupVersion.String(),
+					Kind:       "SlurmNodeSet",
+					Name:       ns.Name,
+					UID:        ns.UID,
+					Controller: &controller,
+				},
+			},
+		},
+		Spec: tpl.Spec,
+	}
+
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+
+	if pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+
+	pod.Labels["host"] = node.Name
+	pod.Labels[NodeSetLabel] = ns.Name
+	pod.Annotations[NodeSetRevisionAnnotation] = revision
+	pod.Spec.Hostname = name
+	pod.Spec.Subdomain = fmt.Sprintf("%s-slurmd", ns.Spec.Cluster)
+	pod.Spec.NodeSelector["kubernetes.io/hostname"] = node.Name
+
+	withNodeGPUs(pod, node)
+
+	return pod
+}
+
+// withNodeGPUs requests the device plugin GPUs of the node for slurmd, so the runtime sets up the devices
+// and driver in the pod. Slurm schedules the GPUs from there, gres.conf lists them.
+func withNodeGPUs(pod *v1.Pod, node *v1.Node) {
+	gpus, ok := node.Status.Allocatable[v1.ResourceName(GPUResource)]
+	if !ok || gpus.IsZero() {
+		return
+	}
+
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name != "slurmd" {
+			continue
+		}
+
+		if pod.Spec.Containers[i].Resources.Limits == nil {
+			pod.Spec.Containers[i].Resources.Limits = v1.ResourceList{}
+		}
+
+		pod.Spec.Containers[i].Resources.Limits[v1.ResourceName(GPUResource)] = gpus
+	}
+}
+
+// nodeSetPodName is the pod, host and slurm node name of the slurmd on a node
+func nodeSetPodName(ns *v1s.SlurmNodeSet, node string) string {
+	return fmt.Sprintf("%s-%s", ns.Spec.Cluster, node)
+}
+
+// clusterOf returns the Slik a SlurmNodeSet belongs to, enough to run slurm commands
+func clusterOf(ns *v1s.SlurmNodeSet) *v1s.Slik {
+	return &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ns.Spec.Cluster,
+			Namespace: ns.Namespace,
+		},
+	}
+}
+
+func templateRevision(tpl *v1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])[:10], nil
+}
+
+func podReady(pod *v1.Pod) bool {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == v1.PodReady {
+			return pod.Status.Conditions[i].Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
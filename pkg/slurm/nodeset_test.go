@@ -0,0 +1,308 @@
+package slurm
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func slurmLabeledNode(name string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				nodeLabelCPUs:           "2",
+				nodeLabelRealMemory:     "1024",
+				nodeLabelThreadsPerCore: "1",
+			},
+		},
+		Spec: corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+func testNodeSet(image string) *v1s.SlurmNodeSet {
+	return &v1s.SlurmNodeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-slurmd", Namespace: "default"},
+		Spec: v1s.SlurmNodeSetSpec{
+			Cluster: "test",
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "test-slurmd"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "slurmd", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func setPodsReady(t *testing.T, client *fake.Clientset) {
+	t.Helper()
+
+	pods, err := client.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+		if _, err := client.CoreV1().Pods("default").Update(context.TODO(), pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReconcileNodeSetCreatesPods(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		slurmLabeledNode("b", false),
+		slurmLabeledNode("cordoned", true),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-a", Namespace: "default"}},
+	)
+
+	ns := testNodeSet("slurmd:1")
+	if err := ReconcileNodeSet(client, &fakeExecutor{}, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err := GetPod(client, "test-a", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pod.Spec.Hostname != "test-a" || pod.Spec.Subdomain != "test-slurmd" ||
+		pod.Spec.NodeSelector["kubernetes.io/hostname"] != "a" || pod.Labels["host"] != "a" {
+		t.Fatalf("unexpected slurmd pod: %+v", pod)
+	}
+
+	if !PodExists(client, "test-b", "default") || PodExists(client, "test-cordoned", "default") ||
+		PodExists(client, "test-unlabeled", "default") {
+		t.Fatal("expected slurmd pods on schedulable labeled nodes only")
+	}
+
+	if DeploymentExists(client, "test-a", "default") {
+		t.Fatal("expected per node deployment to be replaced")
+	}
+
+	if ns.Status.Nodes != 2 || ns.Status.UpdatedNodes != 2 || ns.Status.Revision == "" {
+		t.Fatalf("unexpected status: %+v", ns.Status)
+	}
+}
+
+func TestReconcileNodeSetRollingUpdate(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		slurmLabeledNode("b", false),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+		},
+	)
+	exec := &fakeExecutor{outputs: map[string]string{"squeue": "42\n"}}
+
+	ns := testNodeSet("slurmd:1")
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	setPodsReady(t, client)
+
+	// new template, only one node is drained at a time
+	ns = testNodeSet("slurmd:2")
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ns.Status.Updating) != 1 || ns.Status.Updating[0] != "a" ||
+		exec.commands[len(exec.commands)-1] != "scontrol update nodename=test-a state=DRAIN reason="+NodeSetUpdateReason {
+		t.Fatalf("expected node a to be drained first, got %+v %v", ns.Status, exec.commands)
+	}
+
+	// jobs still running, the pod is kept
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if !PodExists(client, "test-a", "default") {
+		t.Fatal("expected pod to be kept while jobs run")
+	}
+
+	// jobs finished, the pod is replaced with the new template
+	exec.outputs["squeue"] = ""
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err := GetPod(client, "test-a", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pod.Spec.Containers[0].Image != "slurmd:2" || len(ns.Status.Updating) != 1 {
+		t.Fatalf("expected replaced pod waiting to become ready, got %+v", ns.Status)
+	}
+
+	// ready again, the node is resumed and the next one drained
+	setPodsReady(t, client)
+
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ns.Status.Updating) != 1 || ns.Status.Updating[0] != "b" {
+		t.Fatalf("expected node b to be drained next, got %+v", ns.Status)
+	}
+
+	resumed := false
+	for _, command := range exec.commands {
+		if command == "scontrol update nodename=test-a state=RESUME" {
+			resumed = true
+		}
+	}
+
+	if !resumed {
+		t.Fatalf("expected node a to be resumed, got %v", exec.commands)
+	}
+}
+
+func TestReconcileNodeSetMaintenance(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+		},
+	)
+	exec := &fakeExecutor{outputs: map[string]string{}}
+
+	if err := ReconcileNodeSet(client, exec, testNodeSet("slurmd:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	setPodsReady(t, client)
+
+	// drained, replaced and recreated
+	ns := testNodeSet("slurmd:2")
+	for range 3 {
+		if err := ReconcileNodeSet(client, exec, ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the cluster entered maintenance during the update
+	setPodsReady(t, client)
+	exec.outputs[drainedCommand] = "test-a|" + MaintenanceReason + "\n"
+	exec.commands = nil
+
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ns.Status.Updating) != 0 || slices.Contains(exec.commands, "scontrol update nodename=test-a state=RESUME") {
+		t.Fatalf("expected node a to stay drained, got %+v %v", ns.Status, exec.commands)
+	}
+}
+
+func TestReconcileNodeSetKeepsAdminDrain(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+		},
+	)
+	exec := &fakeExecutor{outputs: map[string]string{drainedCommand: "test-a|bad dimm\n"}}
+
+	if err := ReconcileNodeSet(client, exec, testNodeSet("slurmd:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	setPodsReady(t, client)
+
+	// drained, replaced and recreated
+	ns := testNodeSet("slurmd:2")
+	for range 3 {
+		if err := ReconcileNodeSet(client, exec, ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setPodsReady(t, client)
+
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, command := range exec.commands {
+		if strings.HasPrefix(command, "scontrol update nodename=test-a") {
+			t.Fatalf("expected the drain of node a to be kept, got %v", exec.commands)
+		}
+	}
+
+	if len(ns.Status.Updating) != 0 || ns.Status.UpdatedNodes != 1 {
+		t.Fatalf("expected node a to be updated, got %+v", ns.Status)
+	}
+}
+
+func TestReconcileNodeSetMaxUnavailablePerPartition(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		slurmLabeledNode("b", false),
+		slurmLabeledNode("c", false),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+		},
+	)
+	exec := &fakeExecutor{outputs: map[string]string{
+		"squeue":                                 "42\n",
+		"sinfo --noheader --Node --format=%N|%R": "test-a|batch\ntest-b|batch\ntest-c|gpu\n",
+	}}
+
+	if err := ReconcileNodeSet(client, exec, testNodeSet("slurmd:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	setPodsReady(t, client)
+
+	ns := testNodeSet("slurmd:2")
+	ns.Spec.UpdateStrategy.MaxUnavailable = 3
+	ns.Spec.UpdateStrategy.MaxUnavailablePerPartition = 1
+
+	if err := ReconcileNodeSet(client, exec, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(ns.Status.Updating, []string{"a", "c"}) {
+		t.Fatalf("expected one node per partition to be drained, got %+v %v", ns.Status, exec.commands)
+	}
+}
@@ -0,0 +1,121 @@
+package slurm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// slurmConfOwnedKeys slurm.conf parameters rendered by slik, they can not be overridden
+	slurmConfOwnedKeys = []string{
+		"ClusterName", "SlurmctldHost", "ControlMachine", "ControlAddr", "BackupController", "BackupAddr",
+		"SlurmctldPort", "SlurmdPort", "NodeName", "NodeSet", "PartitionName", "DownNodes", "FrontendName",
+		"AccountingStorageType", "AccountingStorageHost", "AccountingStoragePort",
+		"StateSaveLocation", "SlurmdSpoolDir", "SlurmctldPidFile", "SlurmdPidFile", "SlurmUser",
+		"ProctrackType", "TaskPlugin", "GresTypes", "TopologyPlugin", "SlurmctldParameters",
+		"ResumeProgram", "SuspendProgram", "Include",
+		"Prolog", "Epilog", "TaskProlog", "PrologSlurmctld", "EpilogSlurmctld", "JobSubmitPlugins",
+	}
+
+	// slurmdbdConfOwnedKeys slurmdbd.conf parameters rendered by slik, they can not be overridden
+	slurmdbdConfOwnedKeys = []string{
+		"AuthType", "DbdHost", "DbdAddr", "DbdPort", "StorageType", "StorageHost", "StoragePort",
+		"StorageLoc", "StorageUser", "StoragePass", "PidFile", "SlurmUser", "Include",
+	}
+)
+
+// ValidateSlurmConf returns an error if spec.slurmConf sets a parameter slik owns or is malformed
+func ValidateSlurmConf(overrides *v1s.ConfOverrides) error {
+	return validateConfOverrides(overrides, slurmConfOwnedKeys)
+}
+
+// ValidateSlurmdbdConf returns an error if spec.slurmdbdConf sets a parameter slik owns or is malformed
+func ValidateSlurmdbdConf(overrides *v1s.ConfOverrides) error {
+	return validateConfOverrides(overrides, slurmdbdConfOwnedKeys)
+}
+
+func validateConfOverrides(overrides *v1s.ConfOverrides, owned []string) error {
+	for _, param := range overrides.Extra {
+		if param.Key == "" || strings.ContainsAny(param.Key, "= \t\r\n#") {
+			return fmt.Errorf("%w: %q", ErrInvalidConfParameter, param.Key)
+		}
+
+		// a value spanning lines would add parameters past the deny-list
+		if strings.ContainsAny(param.Value, "\r\n") {
+			return fmt.Errorf("%w: value of %s spans lines", ErrInvalidConfParameter, param.Key)
+		}
+
+		if err := checkOwnedKey(param.Key, owned); err != nil {
+			return err
+		}
+	}
+
+	for _, include := range overrides.Includes {
+		if include.ConfigMap == "" || include.Key == "" {
+			return fmt.Errorf("%w: include requires configMap and key", ErrInvalidConfParameter)
+		}
+	}
+
+	return nil
+}
+
+// checkOwnedKey returns ErrOwnedConfKey if key is rendered by slik, slurm keys are case-insensitive
+func checkOwnedKey(key string, owned []string) error {
+	if slices.ContainsFunc(owned, func(o string) bool { return strings.EqualFold(o, key) }) {
+		return fmt.Errorf("%w: %s", ErrOwnedConfKey, key)
+	}
+
+	return nil
+}
+
+// confParameters converts spec parameters for the slurmconf model
+func confParameters(params []v1s.ConfParameter) []slurmconf.Parameter {
+	result := []slurmconf.Parameter{}
+	for _, param := range params {
+		result = append(result, slurmconf.Parameter{Key: param.Key, Value: param.Value})
+	}
+
+	return result
+}
+
+// includeFileName is the key of an included file in the rendered ConfigMap, next to the file including it
+func includeFileName(include *v1s.ConfInclude) string {
+	return fmt.Sprintf("include-%s-%s", include.ConfigMap, include.Key)
+}
+
+// confIncludes reads the included ConfigMap keys by file name. The same deny-list applies to their lines,
+// the ConfigMaps are not validated when the cluster is.
+func confIncludes(client kubernetes.Interface, wl *v1s.Slik, overrides *v1s.ConfOverrides, owned []string) (map[string]string, error) {
+	files := map[string]string{}
+
+	for i := range overrides.Includes {
+		include := &overrides.Includes[i]
+
+		data, err := configMapKey(client, wl.Namespace, include.ConfigMap, include.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		for line := range strings.Lines(data) {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			key, _, _ := strings.Cut(strings.Fields(line)[0], "=")
+			if err := checkOwnedKey(key, owned); err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", include.ConfigMap, include.Key, err)
+			}
+		}
+
+		files[includeFileName(include)] = data
+	}
+
+	return files, nil
+}
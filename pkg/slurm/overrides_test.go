@@ -0,0 +1,131 @@
+package slurm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateSlurmConf(t *testing.T) {
+	valid := &v1s.ConfOverrides{
+		Extra: []v1s.ConfParameter{
+			{Key: "SchedulerParameters", Value: "bf_continue,bf_max_job_test=500"},
+			{Key: "PriorityType", Value: "priority/multifactor"},
+		},
+		Includes: []v1s.ConfInclude{{ConfigMap: "site", Key: "partitions.conf"}},
+	}
+
+	if err := ValidateSlurmConf(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, param := range []v1s.ConfParameter{
+		{Key: "SlurmctldHost", Value: "elsewhere"},
+		{Key: "nodename", Value: "extra"},
+		{Key: "Include", Value: "/etc/passwd"},
+	} {
+		err := ValidateSlurmConf(&v1s.ConfOverrides{Extra: []v1s.ConfParameter{param}})
+		if !errors.Is(err, ErrOwnedConfKey) {
+			t.Fatalf("expected %s to be owned by slik, got %v", param.Key, err)
+		}
+	}
+
+	for _, param := range []v1s.ConfParameter{
+		{Key: "MaxJobCount=1", Value: "2"},
+		{Key: "MaxJobCount", Value: "10000\nSlurmctldHost=elsewhere"},
+	} {
+		err := ValidateSlurmConf(&v1s.ConfOverrides{Extra: []v1s.ConfParameter{param}})
+		if !errors.Is(err, ErrInvalidConfParameter) {
+			t.Fatalf("expected %+v to be rejected, got %v", param, err)
+		}
+	}
+
+	err := ValidateSlurmdbdConf(&v1s.ConfOverrides{Extra: []v1s.ConfParameter{{Key: "StoragePass", Value: "x"}}})
+	if !errors.Is(err, ErrOwnedConfKey) {
+		t.Fatalf("expected StoragePass to be owned by slik, got %v", err)
+	}
+}
+
+func TestSlurmConfOverrides(t *testing.T) {
+	site := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "default"},
+		Data:       map[string]string{"sched.conf": "# site scheduling\nMaxJobCount=50000\n"},
+	}
+	client := fake.NewSimpleClientset(slurmLabeledNode("a", false), site)
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			SlurmConf: v1s.ConfOverrides{
+				Extra: []v1s.ConfParameter{
+					{Key: "SchedulerParameters", Value: "bf_continue"},
+					{Key: "PriorityType", Value: "priority/multifactor"},
+				},
+				Includes: []v1s.ConfInclude{{ConfigMap: "site", Key: "sched.conf"}},
+			},
+		},
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "SchedulerParameters=bf_continue\nPriorityType=priority/multifactor\nInclude /etc/slurm/include-site-sched.conf\n"
+	if !strings.Contains(cm.Data["slurm.conf"], want) {
+		t.Fatalf("expected %q in slurm.conf:\n%s", want, cm.Data["slurm.conf"])
+	}
+
+	if cm.Data["include-site-sched.conf"] != site.Data["sched.conf"] {
+		t.Fatalf("expected included file next to slurm.conf: %+v", cm.Data)
+	}
+
+	// included files may not set parameters slik owns either
+	site.Data["sched.conf"] = "SlurmctldHost=elsewhere\n"
+	if _, err := client.CoreV1().ConfigMaps("default").Update(t.Context(), site, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); !errors.Is(err, ErrOwnedConfKey) {
+		t.Fatalf("expected included SlurmctldHost to be rejected, got %v", err)
+	}
+
+	wl.Spec.SlurmConf.Includes[0].Key = "missing"
+	if err := buildSlurmconfConfigMap(client, wl); !errors.Is(err, ErrConfigMapKeyNotFound) {
+		t.Fatalf("expected missing key to fail, got %v", err)
+	}
+}
+
+func TestSlurmdbdConfOverrides(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			SlurmdbdConf: v1s.ConfOverrides{
+				Extra: []v1s.ConfParameter{{Key: "PurgeJobAfter", Value: "12months"}},
+			},
+		},
+	}
+
+	if err := buildSlurmdbdConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurmdbd", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(cm.Data["slurmdbd.conf"], "\nPurgeJobAfter=12months\n") {
+		t.Fatalf("expected extra parameter in slurmdbd.conf:\n%s", cm.Data["slurmdbd.conf"])
+	}
+}
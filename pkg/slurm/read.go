@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -59,6 +60,30 @@ func StatefulsetExists(client kubernetes.Interface, name, namespace string) bool
 	})
 }
 
+// PersistentVolumeClaimExists returns true if the pvc exists
+func PersistentVolumeClaimExists(client kubernetes.Interface, name, namespace string) bool {
+	return resourceExists(func() error {
+		_, err := GetPersistentVolumeClaim(client, name, namespace)
+		return err
+	})
+}
+
+// SecretExists returns true if the secret exists
+func SecretExists(client kubernetes.Interface, name, namespace string) bool {
+	return resourceExists(func() error {
+		_, err := GetSecret(client, name, namespace)
+		return err
+	})
+}
+
+// PodDisruptionBudgetExists returns true if the pdb exists
+func PodDisruptionBudgetExists(client kubernetes.Interface, name, namespace string) bool {
+	return resourceExists(func() error {
+		_, err := GetPodDisruptionBudget(client, name, namespace)
+		return err
+	})
+}
+
 func resourceExists(get func() error) bool {
 	if err := get(); err != nil {
 		if !errors.IsNotFound(err) {
@@ -96,11 +121,26 @@ func GetConfigMap(client kubernetes.Interface, name, namespace string) (*v1.Conf
 	return client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
+// GetSecret returns the secret if it exists
+func GetSecret(client kubernetes.Interface, name, namespace string) (*v1.Secret, error) {
+	return client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
 // GetStatefulSet returns the statefulset if it exists
 func GetStatefulSet(client kubernetes.Interface, name, namespace string) (*appsv1.StatefulSet, error) {
 	return client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
+// GetPersistentVolumeClaim returns the pvc if it exists
+func GetPersistentVolumeClaim(client kubernetes.Interface, name, namespace string) (*v1.PersistentVolumeClaim, error) {
+	return client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// GetPodDisruptionBudget returns the pdb if it exists
+func GetPodDisruptionBudget(client kubernetes.Interface, name, namespace string) (*policyv1.PodDisruptionBudget, error) {
+	return client.PolicyV1().PodDisruptionBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
 // GetNode gets a node
 func GetNode(client kubernetes.Interface, name string) (*v1.Node, error) {
 	return client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
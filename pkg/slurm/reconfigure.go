@@ -0,0 +1,185 @@
+package slurm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// liveSlurmConfKeys slurm.conf lines that are applied with scontrol reconfigure, any other change restarts the pods
+var liveSlurmConfKeys = []string{"NodeName=", "PartitionName="}
+
+// liveSlurmConfFiles files of the slurm ConfigMap that are applied with scontrol reconfigure and read by new
+// pods on start: the switches that change as nodes come and go, and job_submit.lua which only slurmctld runs.
+// gres.conf is not among them, slurmd only binds the GPU devices on start.
+var liveSlurmConfFiles = []string{"topology.conf", "job_submit.lua"}
+
+// liveSlurmConfPrefix prefix of the include files of spec.slurmConf, applied with scontrol reconfigure
+const liveSlurmConfPrefix = "include-"
+
+// liveSlurmConfFile returns true if the file of the slurm ConfigMap is applied with scontrol reconfigure
+func liveSlurmConfFile(file string) bool {
+	return slices.Contains(liveSlurmConfFiles, file) || strings.HasPrefix(file, liveSlurmConfPrefix)
+}
+
+// restartSlurmConf returns slurm.conf without the lines that can be applied live. Unless liveNodes, the names
+// of the NodeName lines are kept, so adding or removing a node restarts the pods while attribute changes do not.
+func restartSlurmConf(conf string, liveNodes bool) string {
+	var b strings.Builder
+	nodes := []string{}
+
+	for line := range strings.Lines(conf) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		live := false
+		for _, key := range liveSlurmConfKeys {
+			if strings.HasPrefix(trimmed, key) {
+				live = true
+			}
+		}
+
+		if !live {
+			b.WriteString(trimmed)
+			b.WriteString("\n")
+		}
+
+		if rest, ok := strings.CutPrefix(trimmed, "NodeName="); ok && !liveNodes {
+			name, _, _ := strings.Cut(rest, " ")
+			nodes = append(nodes, name)
+		}
+	}
+
+	if len(nodes) > 0 {
+		slices.Sort(nodes)
+		b.WriteString("NodeName=")
+		b.WriteString(strings.Join(nodes, ","))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// slurmConfChecksumAnnotations returns the checksum annotation of the slurm ConfigMap, only counting
+// changes that need a restart
+func slurmConfChecksumAnnotations(client kubernetes.Interface, wl *v1s.Slik) map[string]string {
+	name := fmt.Sprintf("%s-slurm", wl.Name)
+
+	cm, err := GetConfigMap(client, name, wl.Namespace)
+	if err != nil {
+		return map[string]string{}
+	}
+
+	data := maps.Clone(cm.Data)
+	data["slurm.conf"] = restartSlurmConf(data["slurm.conf"], VersionAtLeast(LiveNodesMinSlurmVersion))
+
+	maps.DeleteFunc(data, func(file, _ string) bool {
+		return liveSlurmConfFile(file)
+	})
+
+	return map[string]string{
+		fmt.Sprintf("slik.vultr.com/checksum-%s", name): checksumData(data, cm.BinaryData),
+	}
+}
+
+// Reconfigure runs scontrol reconfigure once slurm.conf changed and the kubelet projected it into
+// every slurmctld and slurmd pod. The applied checksum is kept in wl.Status.SlurmConfChecksum.
+func Reconfigure(client kubernetes.Interface, exec Executor, wl *v1s.Slik) error {
+	log := zap.L().Sugar()
+
+	cm, err := GetConfigMap(client, fmt.Sprintf("%s-slurm", wl.Name), wl.Namespace)
+	if err != nil {
+		return err
+	}
+
+	checksum := checksumData(cm.Data, cm.BinaryData)
+	if wl.Status.SlurmConfChecksum == checksum {
+		return nil
+	}
+
+	// the cluster was started with this configuration
+	if wl.Status.SlurmConfChecksum == "" {
+		wl.Status.SlurmConfChecksum = checksum
+
+		return nil
+	}
+
+	// the kubelet updates all files of the ConfigMap at once, every one is compared
+	want := map[string]string{}
+	for file, data := range cm.Data {
+		sum := sha256.Sum256([]byte(data))
+		want[path.Join("/etc/slurm", file)] = hex.EncodeToString(sum[:])
+	}
+
+	command := append([]string{"sha256sum"}, slices.Sorted(maps.Keys(want))...)
+
+	// configless slurmd pods get slurm.conf from slurmctld on reconfigure
+	components := []string{"slurmctld", "slurmd"}
+	if wl.Spec.Configless {
+		components = []string{"slurmctld"}
+	}
+
+	for _, component := range components {
+		pods, err := client.CoreV1().Pods(wl.Namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("app=%s-%s", wl.Name, component),
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+				continue
+			}
+
+			out, err := exec.Exec(wl.Namespace, pod.Name, component, command)
+			if err != nil {
+				return err
+			}
+
+			if !projected(out, want) {
+				log.Infof("waiting for slurm.conf to be updated in pod %s", pod.Name)
+
+				return nil
+			}
+		}
+	}
+
+	log.Infof("reconfiguring slurm cluster %s", wl.Name)
+
+	if _, err := slurmCommand(client, exec, wl, "scontrol", "reconfigure"); err != nil {
+		return err
+	}
+
+	wl.Status.SlurmConfChecksum = checksum
+
+	return nil
+}
+
+// projected returns true if the sha256sum output lists the wanted checksum for every file
+func projected(out string, want map[string]string) bool {
+	matched := 0
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && want[fields[1]] == fields[0] {
+			matched++
+		}
+	}
+
+	return matched == len(want)
+}
@@ -0,0 +1,141 @@
+package slurm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestartSlurmConf(t *testing.T) {
+	before := "ClusterName=cluster\nNodeName=test-a CPUs=2\nPartitionName=batch Nodes=ALL\n"
+	nodeAdded := "ClusterName=cluster\n# nodes\nNodeName=test-a CPUs=2\nNodeName=test-b CPUs=4\nPartitionName=batch Nodes=ALL MaxTime=60\n"
+	renamed := "ClusterName=other\nNodeName=test-a CPUs=2\nPartitionName=batch Nodes=ALL\n"
+
+	nodeChanged := "ClusterName=cluster\nNodeName=test-a CPUs=4\nPartitionName=batch Nodes=ALL MaxTime=60\n"
+
+	if restartSlurmConf(before, true) != restartSlurmConf(nodeAdded, true) {
+		t.Error("expected node and partition changes to be applied live")
+	}
+
+	if restartSlurmConf(before, false) == restartSlurmConf(nodeAdded, false) {
+		t.Error("expected added nodes to need a restart before Slurm 23.11")
+	}
+
+	if restartSlurmConf(before, false) != restartSlurmConf(nodeChanged, false) {
+		t.Error("expected node attribute and partition changes to be applied live")
+	}
+
+	if restartSlurmConf(before, true) == restartSlurmConf(renamed, true) {
+		t.Error("expected cluster name change to need a restart")
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	conf := "ClusterName=cluster\nNodeName=test-a CPUs=2\n"
+	sum := sha256.Sum256([]byte(conf))
+
+	client := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-slurm", Namespace: "default"},
+			Data:       map[string]string{"slurm.conf": conf},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-slurmctld-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "test-slurmctld"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	exec := &fakeExecutor{outputs: map[string]string{"sha256sum": "stale  /etc/slurm/slurm.conf\n"}}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status:     v1s.SlikStatus{SlurmConfChecksum: "previous"},
+	}
+
+	// the kubelet has not projected the new slurm.conf yet
+	if err := Reconfigure(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if wl.Status.SlurmConfChecksum != "previous" || len(exec.commands) != 1 {
+		t.Fatalf("expected to wait for slurm.conf, got %v", exec.commands)
+	}
+
+	exec.outputs["sha256sum"] = hex.EncodeToString(sum[:]) + "  /etc/slurm/slurm.conf\n"
+	if err := Reconfigure(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if exec.commands[len(exec.commands)-1] != "scontrol reconfigure" || wl.Status.SlurmConfChecksum == "previous" {
+		t.Fatalf("expected scontrol reconfigure, got %v", exec.commands)
+	}
+
+	// applied configuration is not reconfigured again
+	exec.commands = nil
+	if err := Reconfigure(client, exec, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.commands) != 0 {
+		t.Fatalf("expected no commands, got %v", exec.commands)
+	}
+}
+
+func TestSlurmConfChecksumLiveFiles(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-slurm", Namespace: "default"},
+		Data: map[string]string{
+			"slurm.conf":              "ClusterName=cluster\nInclude /etc/slurm/include-site-sched.conf\n",
+			"job_submit.lua":          "return slurm.SUCCESS\n",
+			"include-site-sched.conf": "SchedulerParameters=bf_continue\n",
+		},
+	}
+
+	client := fake.NewSimpleClientset(cm)
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	before := slurmConfChecksumAnnotations(client, wl)
+
+	cm.Data["job_submit.lua"] = "slurm.log_info(\"submit\")\nreturn slurm.SUCCESS\n"
+	cm.Data["include-site-sched.conf"] = "SchedulerParameters=bf_continue,bf_interval=60\n"
+	if _, err := client.CoreV1().ConfigMaps("default").Update(t.Context(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := slurmConfChecksumAnnotations(client, wl); after["slik.vultr.com/checksum-test-slurm"] != before["slik.vultr.com/checksum-test-slurm"] {
+		t.Fatal("expected job_submit.lua and include changes not to restart the pods")
+	}
+}
+
+func TestSlurmConfChecksumGresConf(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-slurm", Namespace: "default"},
+		Data: map[string]string{
+			"slurm.conf": "ClusterName=cluster\nGresTypes=gpu\n",
+			"gres.conf":  "NodeName=test-a Name=gpu Type=a100 File=/dev/nvidia[0-3]\n",
+		},
+	}
+
+	client := fake.NewSimpleClientset(cm)
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	before := slurmConfChecksumAnnotations(client, wl)
+
+	cm.Data["gres.conf"] = "NodeName=test-a Name=gpu Type=a100 Count=2\n"
+	if _, err := client.CoreV1().ConfigMaps("default").Update(t.Context(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := slurmConfChecksumAnnotations(client, wl); after["slik.vultr.com/checksum-test-slurm"] == before["slik.vultr.com/checksum-test-slurm"] {
+		t.Fatal("expected a gres.conf change to restart the slurmd pods")
+	}
+}
@@ -0,0 +1,211 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Render runs CreateSlurm and BuildSlurmdNodeSet against a fake clientset seeded with fixtures, Nodes and the
+// ConfigMaps and Secrets referenced by the spec, and returns the objects slik would create sorted by kind
+// and name. Workloads are reported available as they are created, so nothing is waited for.
+func Render(wl *v1s.Slik, fixtures []runtime.Object) ([]runtime.Object, error) {
+	seeded := map[string]bool{}
+	nodes := []v1.Node{}
+
+	for _, obj := range fixtures {
+		switch o := obj.(type) {
+		case *v1.Node:
+			nodes = append(nodes, *o)
+		case *v1.ConfigMap, *v1.Secret:
+		default:
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedFixture, obj)
+		}
+
+		seeded[renderKey(obj)] = true
+	}
+
+	nodeSets := &renderNodeSets{items: map[string]*v1s.SlurmNodeSet{}}
+
+	objs, err := renderCluster(fake.NewSimpleClientset(fixtures...), nodeSets, wl, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(objs, func(obj runtime.Object) bool { return seeded[renderKey(obj)] }), nil
+}
+
+// renderCluster runs CreateSlurm and BuildSlurmdNodeSet against the fake clientset and node sets and returns
+// the resulting objects. The nodes must be labeled, they are not waited for.
+func renderCluster(client *fake.Clientset, nodeSets *renderNodeSets, wl *v1s.Slik, nodes []v1.Node) ([]runtime.Object, error) {
+	if pending := pendingSlurmableLabels(nodes); len(pending) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNodesNotLabeled, pending)
+	}
+
+	client.PrependReactor("create", "*", renderAvailable)
+	client.PrependReactor("update", "*", renderAvailable)
+
+	if err := CreateSlurm(client, wl); err != nil {
+		return nil, err
+	}
+
+	if err := BuildSlurmdNodeSet(client, nodeSets, wl); err != nil {
+		return nil, err
+	}
+
+	objs, err := renderObjects(client, metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(nodeSets.items)) {
+		nodeSet := nodeSets.items[name]
+		nodeSet.TypeMeta = metav1.TypeMeta{APIVersion: v1s.SchemeGroupVersion.String(), Kind: "SlurmNodeSet"}
+		objs = append(objs, nodeSet)
+	}
+
+	return objs, nil
+}
+
+// renderAvailable marks created workloads available, CreateSlurm waits for slurmdbd
+func renderAvailable(action k8stesting.Action) (bool, runtime.Object, error) {
+	var obj runtime.Object
+	switch a := action.(type) {
+	case k8stesting.CreateAction:
+		obj = a.GetObject()
+	case k8stesting.UpdateAction:
+		obj = a.GetObject()
+	}
+
+	if dep, ok := obj.(*appsv1.Deployment); ok {
+		var replicas int32 = 1
+		if dep.Spec.Replicas != nil {
+			replicas = *dep.Spec.Replicas
+		}
+
+		dep.Status.Replicas = replicas
+		dep.Status.AvailableReplicas = replicas
+	}
+
+	// not handled, the object is stored by the next reactor
+	return false, nil, nil
+}
+
+// renderObjects lists the objects of the kinds slik creates in namespace, with their kind set for printing
+func renderObjects(client kubernetes.Interface, namespace string) ([]runtime.Object, error) {
+	ctx := context.TODO()
+	opts := metav1.ListOptions{}
+	core := client.CoreV1()
+	apps := client.AppsV1()
+
+	lists := []struct {
+		gvk  schema.GroupVersionKind
+		list func() (runtime.Object, error)
+	}{
+		{v1.SchemeGroupVersion.WithKind("Namespace"), func() (runtime.Object, error) { return core.Namespaces().List(ctx, opts) }},
+		{v1.SchemeGroupVersion.WithKind("ConfigMap"), func() (runtime.Object, error) { return core.ConfigMaps(namespace).List(ctx, opts) }},
+		{v1.SchemeGroupVersion.WithKind("Secret"), func() (runtime.Object, error) { return core.Secrets(namespace).List(ctx, opts) }},
+		{v1.SchemeGroupVersion.WithKind("Service"), func() (runtime.Object, error) { return core.Services(namespace).List(ctx, opts) }},
+		{v1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), func() (runtime.Object, error) {
+			return core.PersistentVolumeClaims(namespace).List(ctx, opts)
+		}},
+		{appsv1.SchemeGroupVersion.WithKind("DaemonSet"), func() (runtime.Object, error) { return apps.DaemonSets(namespace).List(ctx, opts) }},
+		{appsv1.SchemeGroupVersion.WithKind("Deployment"), func() (runtime.Object, error) { return apps.Deployments(namespace).List(ctx, opts) }},
+		{appsv1.SchemeGroupVersion.WithKind("StatefulSet"), func() (runtime.Object, error) { return apps.StatefulSets(namespace).List(ctx, opts) }},
+	}
+
+	objs := []runtime.Object{}
+	for _, l := range lists {
+		list, err := l.list()
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.SortFunc(items, func(a, b runtime.Object) int { return strings.Compare(renderKey(a), renderKey(b)) })
+
+		for _, item := range items {
+			// the status was only set to skip waiting
+			if dep, ok := item.(*appsv1.Deployment); ok {
+				dep.Status = appsv1.DeploymentStatus{}
+			}
+
+			item.GetObjectKind().SetGroupVersionKind(l.gvk)
+			objs = append(objs, item)
+		}
+	}
+
+	return objs, nil
+}
+
+// renderKey identifies an object by type, namespace and name
+func renderKey(obj runtime.Object) string {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+
+	return fmt.Sprintf("%T/%s/%s", obj, o.GetNamespace(), o.GetName())
+}
+
+// renderNodeSets keeps the SlurmNodeSet in memory, it is a custom resource the fake clientset does not serve
+type renderNodeSets struct {
+	items map[string]*v1s.SlurmNodeSet
+}
+
+func (r *renderNodeSets) List(_ metav1.ListOptions) (*v1s.SlurmNodeSetList, error) {
+	list := &v1s.SlurmNodeSetList{}
+	for _, item := range r.items {
+		list.Items = append(list.Items, *item)
+	}
+
+	return list, nil
+}
+
+func (r *renderNodeSets) Get(name string, _ metav1.GetOptions) (*v1s.SlurmNodeSet, error) {
+	item, ok := r.items[name]
+	if !ok {
+		return nil, errors.NewNotFound(v1s.SchemeGroupVersion.WithResource("slurmnodesets").GroupResource(), name)
+	}
+
+	return item.DeepCopy(), nil
+}
+
+func (r *renderNodeSets) Create(nodeSet *v1s.SlurmNodeSet) (*v1s.SlurmNodeSet, error) {
+	r.items[nodeSet.Name] = nodeSet.DeepCopy()
+
+	return nodeSet, nil
+}
+
+func (r *renderNodeSets) Update(nodeSet *v1s.SlurmNodeSet, _ metav1.UpdateOptions) (*v1s.SlurmNodeSet, error) {
+	return r.Create(nodeSet)
+}
+
+func (r *renderNodeSets) UpdateStatus(nodeSet *v1s.SlurmNodeSet, _ metav1.UpdateOptions) (*v1s.SlurmNodeSet, error) {
+	return r.Create(nodeSet)
+}
+
+func (r *renderNodeSets) Delete(name string, _ metav1.DeleteOptions) error {
+	delete(r.items, name)
+
+	return nil
+}
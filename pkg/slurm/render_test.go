@@ -0,0 +1,68 @@
+package slurm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRender(t *testing.T) {
+	site := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "default"},
+		Data:       map[string]string{"prolog.sh": "#!/bin/sh\n"},
+	}
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Namespace: "default",
+			Slurmdbd:  true,
+			MariaDB:   v1s.MariaDB{StorageSize: "10G"},
+			Scripts:   v1s.Scripts{Prolog: &v1s.ScriptRef{ConfigMap: "site", Key: "prolog.sh"}},
+		},
+	}
+
+	objs, err := Render(wl, []runtime.Object{slurmLabeledNode("a", false), site})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]int{}
+	for _, obj := range objs {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		kinds[kind]++
+
+		if cm, ok := obj.(*corev1.ConfigMap); ok {
+			if cm.Name == "site" {
+				t.Fatal("expected the fixtures not to be rendered")
+			}
+
+			if cm.Name == "test-slurm" && !strings.Contains(cm.Data["slurm.conf"], "NodeName=test-a ") {
+				t.Fatalf("expected the fixture node in slurm.conf:\n%s", cm.Data["slurm.conf"])
+			}
+		}
+
+		if dep, ok := obj.(*appsv1.Deployment); ok && dep.Status.AvailableReplicas != 0 {
+			t.Fatalf("expected no status on %s", dep.Name)
+		}
+	}
+
+	for kind, want := range map[string]int{"Deployment": 3, "StatefulSet": 1, "SlurmNodeSet": 1, "DaemonSet": 1} {
+		if kinds[kind] != want {
+			t.Fatalf("expected %d %s, got %+v", want, kind, kinds)
+		}
+	}
+
+	// nodes are not waited for
+	unlabeled := slurmLabeledNode("b", false)
+	unlabeled.Labels = nil
+	if _, err := Render(wl, []runtime.Object{unlabeled}); !errors.Is(err, ErrNodesNotLabeled) {
+		t.Fatalf("expected unlabeled nodes to fail, got %v", err)
+	}
+}
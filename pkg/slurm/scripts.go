@@ -0,0 +1,128 @@
+package slurm
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// script a spec.scripts entry run from ScriptsMount, with its slurm.conf parameter and the component running it
+type script struct {
+	key       string
+	file      string
+	component string
+	ref       *v1s.ScriptRef
+}
+
+// scripts returns the configured prolog and epilog scripts, job_submit.lua is rendered next to slurm.conf instead
+func scripts(wl *v1s.Slik) []script {
+	s := &wl.Spec.Scripts
+
+	all := []script{
+		{key: "Prolog", file: "prolog", component: v1s.ComponentSlurmd, ref: s.Prolog},
+		{key: "Epilog", file: "epilog", component: v1s.ComponentSlurmd, ref: s.Epilog},
+		{key: "TaskProlog", file: "task_prolog", component: v1s.ComponentSlurmd, ref: s.TaskProlog},
+		{key: "PrologSlurmctld", file: "prolog_slurmctld", component: v1s.ComponentSlurmctld, ref: s.PrologSlurmctld},
+		{key: "EpilogSlurmctld", file: "epilog_slurmctld", component: v1s.ComponentSlurmctld, ref: s.EpilogSlurmctld},
+	}
+
+	return slices.DeleteFunc(all, func(s script) bool { return s.ref == nil })
+}
+
+// scriptParameters returns the slurm.conf lines of the configured scripts
+func scriptParameters(wl *v1s.Slik) []slurmconf.Parameter {
+	params := []slurmconf.Parameter{}
+	for _, s := range scripts(wl) {
+		params = append(params, slurmconf.Parameter{Key: s.key, Value: path.Join(ScriptsMount, s.file)})
+	}
+
+	if wl.Spec.Scripts.JobSubmit != nil {
+		params = append(params, slurmconf.Parameter{Key: "JobSubmitPlugins", Value: "lua"})
+	}
+
+	return params
+}
+
+// withScripts mounts the scripts of component executable into the container, the pods roll when the
+// referenced ConfigMaps change
+func withScripts(client kubernetes.Interface, tpl *v1.PodTemplateSpec, wl *v1s.Slik, component, container string) {
+	var mode int32 = 0o755
+
+	sources := []v1.VolumeProjection{}
+	configMaps := []string{}
+
+	for _, s := range scripts(wl) {
+		if s.component != component {
+			continue
+		}
+
+		sources = append(sources, v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: s.ref.ConfigMap,
+				},
+				Items: []v1.KeyToPath{
+					{Key: s.ref.Key, Path: s.file},
+				},
+			},
+		})
+
+		if !slices.Contains(configMaps, s.ref.ConfigMap) {
+			configMaps = append(configMaps, s.ref.ConfigMap)
+		}
+	}
+
+	if len(sources) == 0 {
+		return
+	}
+
+	tpl.Spec.Volumes = append(tpl.Spec.Volumes, v1.Volume{
+		Name: "scripts",
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{
+				Sources:     sources,
+				DefaultMode: &mode,
+			},
+		},
+	})
+
+	for i := range tpl.Spec.Containers {
+		if tpl.Spec.Containers[i].Name != container {
+			continue
+		}
+
+		tpl.Spec.Containers[i].VolumeMounts = append(tpl.Spec.Containers[i].VolumeMounts, v1.VolumeMount{
+			Name:      "scripts",
+			MountPath: ScriptsMount,
+			ReadOnly:  true,
+		})
+	}
+
+	if tpl.Annotations == nil {
+		tpl.Annotations = map[string]string{}
+	}
+
+	maps.Copy(tpl.Annotations, configChecksumAnnotations(client, wl.Namespace, configMaps...))
+}
+
+// configMapKey returns a key of a ConfigMap in the namespace of the cluster
+func configMapKey(client kubernetes.Interface, namespace, name, key string) (string, error) {
+	cm, err := GetConfigMap(client, name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	data, ok := cm.Data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s/%s", ErrConfigMapKeyNotFound, name, key)
+	}
+
+	return data, nil
+}
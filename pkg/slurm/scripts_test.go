@@ -0,0 +1,134 @@
+package slurm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func scriptsCluster() (*fake.Clientset, *v1s.Slik) {
+	client := fake.NewSimpleClientset(
+		slurmLabeledNode("a", false),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "site-scripts", Namespace: "default"},
+			Data: map[string]string{
+				"prolog.sh":      "#!/bin/sh\nexit 0\n",
+				"epilog.sh":      "#!/bin/sh\nexit 0\n",
+				"ctld.sh":        "#!/bin/sh\nexit 0\n",
+				"job_submit.lua": "function slurm_job_submit(job_desc, part_list, submit_uid) return slurm.SUCCESS end\n",
+			},
+		},
+	)
+
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Scripts: v1s.Scripts{
+				Prolog:          &v1s.ScriptRef{ConfigMap: "site-scripts", Key: "prolog.sh"},
+				Epilog:          &v1s.ScriptRef{ConfigMap: "site-scripts", Key: "epilog.sh"},
+				PrologSlurmctld: &v1s.ScriptRef{ConfigMap: "site-scripts", Key: "ctld.sh"},
+				JobSubmit:       &v1s.ScriptRef{ConfigMap: "site-scripts", Key: "job_submit.lua"},
+			},
+		},
+	}
+
+	return client, wl
+}
+
+func TestScriptsSlurmConf(t *testing.T) {
+	client, wl := scriptsCluster()
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\nProlog=/etc/slik/scripts/prolog\nEpilog=/etc/slik/scripts/epilog\n" +
+		"PrologSlurmctld=/etc/slik/scripts/prolog_slurmctld\nJobSubmitPlugins=lua\n"
+	if !strings.Contains(cm.Data["slurm.conf"], want) {
+		t.Fatalf("expected %q in slurm.conf:\n%s", want, cm.Data["slurm.conf"])
+	}
+
+	if !strings.HasPrefix(cm.Data["job_submit.lua"], "function slurm_job_submit") {
+		t.Fatalf("expected job_submit.lua next to slurm.conf: %+v", cm.Data)
+	}
+
+	wl.Spec.Scripts.JobSubmit.Key = "missing"
+	if err := buildSlurmconfConfigMap(client, wl); !errors.Is(err, ErrConfigMapKeyNotFound) {
+		t.Fatalf("expected missing key to fail, got %v", err)
+	}
+}
+
+func TestWithScripts(t *testing.T) {
+	client, wl := scriptsCluster()
+
+	slurmd, err := mkSlurmdPodTemplate(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slurmctld, err := mkSlurmctldPodTemplate(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tpl, want := range map[*corev1.PodTemplateSpec][]string{
+		slurmd:    {"prolog", "epilog"},
+		slurmctld: {"prolog_slurmctld"},
+	} {
+		var files []string
+		for _, volume := range tpl.Spec.Volumes {
+			if volume.Name != "scripts" {
+				continue
+			}
+
+			if *volume.Projected.DefaultMode != 0o755 {
+				t.Fatalf("expected executable scripts, got %o", *volume.Projected.DefaultMode)
+			}
+
+			for _, source := range volume.Projected.Sources {
+				files = append(files, source.ConfigMap.Items[0].Path)
+			}
+		}
+
+		if strings.Join(files, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected scripts %v, got %v", want, files)
+		}
+
+		if tpl.Annotations["slik.vultr.com/checksum-site-scripts"] == "" {
+			t.Fatalf("expected pods to roll with the scripts: %+v", tpl.Annotations)
+		}
+	}
+
+	// the checksum follows the referenced ConfigMap
+	before := slurmd.Annotations["slik.vultr.com/checksum-site-scripts"]
+
+	cm, err := GetConfigMap(client, "site-scripts", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm.Data["prolog.sh"] = "#!/bin/sh\nexit 1\n"
+	if _, err := client.CoreV1().ConfigMaps("default").Update(t.Context(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	slurmd, err = mkSlurmdPodTemplate(client, wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slurmd.Annotations["slik.vultr.com/checksum-site-scripts"] == before {
+		t.Fatal("expected the checksum to change with the script")
+	}
+}
@@ -0,0 +1,135 @@
+package slurm
+
+import (
+	"fmt"
+	"slices"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// mkSharedVolumes returns the volumes and mounts of spec.sharedVolumes for a component
+func mkSharedVolumes(wl *v1s.Slik, component string) ([]v1.Volume, []v1.VolumeMount) {
+	volumes := []v1.Volume{}
+	mounts := []v1.VolumeMount{}
+
+	for i := range wl.Spec.SharedVolumes {
+		sv := &wl.Spec.SharedVolumes[i]
+		if !sharedVolumeMountedIn(sv, component) {
+			continue
+		}
+
+		name := fmt.Sprintf("shared-%s", sv.Name)
+
+		vol := v1.Volume{Name: name}
+		switch {
+		case sv.ClaimName != "":
+			vol.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: sv.ClaimName,
+				ReadOnly:  sv.ReadOnly,
+			}
+		case sv.NFS != nil:
+			vol.NFS = sv.NFS.DeepCopy()
+		case sv.HostPath != nil:
+			vol.HostPath = sv.HostPath.DeepCopy()
+		}
+
+		volumes = append(volumes, vol)
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: sv.MountPath,
+			ReadOnly:  sv.ReadOnly,
+		})
+	}
+
+	return volumes, mounts
+}
+
+// withSharedVolumes adds the shared volumes of a component to a pod spec, mounting them in the named container
+func withSharedVolumes(spec *v1.PodSpec, wl *v1s.Slik, component, container string) {
+	volumes, mounts := mkSharedVolumes(wl, component)
+	if len(volumes) == 0 {
+		return
+	}
+
+	spec.Volumes = append(spec.Volumes, volumes...)
+
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == container {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, mounts...)
+		}
+	}
+}
+
+func sharedVolumeMountedIn(sv *v1s.SharedVolume, component string) bool {
+	return len(sv.Components) == 0 || slices.Contains(sv.Components, component)
+}
+
+// componentPods returns the number of pods of a component. slurmd counts as several, the node set grows
+// with the nodes of the cluster.
+func componentPods(wl *v1s.Slik, component string) int32 {
+	switch component {
+	case v1s.ComponentSlurmd:
+		return 2
+	case v1s.ComponentSlurmctld:
+		if wl.Spec.Slurmctld.HighAvailability {
+			return 2
+		}
+
+		return 1
+	case v1s.ComponentSlurmrestd:
+		if wl.Spec.Slurmdbd && wl.Spec.Slurmrestd {
+			return 1
+		}
+	case v1s.ComponentToolbox:
+		return 1
+	case v1s.ComponentLogin:
+		return loginReplicas(wl)
+	}
+
+	return 0
+}
+
+// validateSharedVolumes checks that claims mounted by more than one pod can be shared, the pods may be
+// scheduled on different nodes
+func validateSharedVolumes(client kubernetes.Interface, wl *v1s.Slik) error {
+	for i := range wl.Spec.SharedVolumes {
+		sv := &wl.Spec.SharedVolumes[i]
+		if sv.ClaimName == "" {
+			continue
+		}
+
+		var pods int32
+		for _, component := range []string{
+			v1s.ComponentSlurmd,
+			v1s.ComponentSlurmctld,
+			v1s.ComponentSlurmrestd,
+			v1s.ComponentToolbox,
+			v1s.ComponentLogin,
+		} {
+			if sharedVolumeMountedIn(sv, component) {
+				pods += componentPods(wl, component)
+			}
+		}
+
+		if pods <= 1 {
+			continue
+		}
+
+		pvc, err := GetPersistentVolumeClaim(client, sv.ClaimName, wl.Namespace)
+		if err != nil {
+			return fmt.Errorf("shared volume %s: %w", sv.Name, err)
+		}
+
+		if slices.Contains(pvc.Spec.AccessModes, v1.ReadWriteMany) ||
+			(sv.ReadOnly && slices.Contains(pvc.Spec.AccessModes, v1.ReadOnlyMany)) {
+			continue
+		}
+
+		return fmt.Errorf("%w: shared volume %s, claim %s", ErrSharedVolumeNotShareable, sv.Name, sv.ClaimName)
+	}
+
+	return nil
+}
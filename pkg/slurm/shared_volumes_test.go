@@ -0,0 +1,125 @@
+package slurm
+
+import (
+	"errors"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMkSharedVolumes(t *testing.T) {
+	wl := &v1s.Slik{
+		Spec: v1s.SlikSpec{
+			SharedVolumes: []v1s.SharedVolume{
+				{Name: "home", MountPath: "/home", ClaimName: "home"},
+				{
+					Name:       "scratch",
+					MountPath:  "/scratch",
+					ReadOnly:   true,
+					Components: []string{v1s.ComponentSlurmd},
+					NFS:        &v1.NFSVolumeSource{Server: "nfs", Path: "/scratch"},
+				},
+			},
+		},
+	}
+
+	volumes, mounts := mkSharedVolumes(wl, v1s.ComponentSlurmd)
+	if len(volumes) != 2 || len(mounts) != 2 {
+		t.Fatalf("expected both volumes on slurmd, got %v", volumes)
+	}
+
+	if volumes[0].PersistentVolumeClaim == nil || volumes[1].NFS == nil || !mounts[1].ReadOnly {
+		t.Fatalf("unexpected slurmd volumes: %+v %+v", volumes, mounts)
+	}
+
+	volumes, _ = mkSharedVolumes(wl, v1s.ComponentToolbox)
+	if len(volumes) != 1 || volumes[0].Name != "shared-home" {
+		t.Fatalf("expected only home on toolbox, got %v", volumes)
+	}
+}
+
+type Fixture7 struct {
+	accessModes []v1.PersistentVolumeAccessMode
+	readOnly    bool
+	components  []string
+
+	result      error
+	description string
+}
+
+func TestValidateSharedVolumes(t *testing.T) {
+	fixtures := []Fixture7{
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+
+			result:      nil,
+			description: "rwx claim on slurmd",
+		},
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+
+			result:      ErrSharedVolumeNotShareable,
+			description: "rwo claim on slurmd",
+		},
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadOnlyMany},
+			readOnly:    true,
+
+			result:      nil,
+			description: "read only rox claim on slurmd",
+		},
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			components:  []string{v1s.ComponentToolbox},
+
+			result:      nil,
+			description: "rwo claim on toolbox only",
+		},
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			components:  []string{v1s.ComponentToolbox, v1s.ComponentSlurmctld},
+
+			result:      ErrSharedVolumeNotShareable,
+			description: "rwo claim on toolbox and slurmctld",
+		},
+		{
+			accessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			components:  []string{v1s.ComponentToolbox, v1s.ComponentSlurmrestd},
+
+			result:      nil,
+			description: "rwo claim on toolbox and disabled slurmrestd",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		client := fake.NewSimpleClientset(&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+			Spec:       v1.PersistentVolumeClaimSpec{AccessModes: fixture.accessModes},
+		})
+
+		wl := &v1s.Slik{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: v1s.SlikSpec{
+				SharedVolumes: []v1s.SharedVolume{
+					{
+						Name:       "home",
+						MountPath:  "/home",
+						ReadOnly:   fixture.readOnly,
+						Components: fixture.components,
+						ClaimName:  "home",
+					},
+				},
+			},
+		}
+
+		result := validateSharedVolumes(client, wl)
+
+		if !errors.Is(result, fixture.result) {
+			t.Errorf("\n%s\nexpect: %s\nactual: %s", fixture.description, fixture.result, result)
+		}
+	}
+}
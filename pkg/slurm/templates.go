@@ -1,70 +1,40 @@
 package slurm
 
 var (
-	slurmConfTpl = `
-{{ $slikName := .SlikName }}
-ClusterName=cluster
-SlurmctldHost={{ $slikName }}-slurmctld
-ProctrackType=proctrack/linuxproc
-ReturnToService=2
-SlurmctldPidFile=/run/slurmctld.pid
-SlurmdPidFile=/run/slurmd.pid
-SlurmdSpoolDir=/var/lib/slurm/slurmd
-StateSaveLocation=/var/lib/slurm/slurmctld
-SlurmUser=root
-TaskPlugin=task/none
-SchedulerType=sched/backfill
-SelectType=select/cons_tres
-SelectTypeParameters=CR_Core_Memory
-JobCompType=jobcomp/none
-JobAcctGatherType=jobacct_gather/none
-SlurmctldDebug=verbose
-SlurmctldLogFile=/var/log/slurm/slurmctld.log
-SlurmdDebug=verbose
-SlurmdLogFile=/var/log/slurm/slurmd.log
+	sssdConfTpl = `
+[sssd]
+services = nss, pam
+domains = LDAP
 
-# slurmdbd
-{{ if .Slurmdbd -}}
-AccountingStorageType=accounting_storage/slurmdbd
-AccountingStoragePort=6819
-AccountingStorageHost={{ $slikName }}-slurmdbd
-{{ else }}
-AccountingStorageType=accounting_storage/none
-{{ end }}
-
-# nodes
-NodeName=DEFAULT State=UNKNOWN CPUs=1 CoresPerSocket=1 ThreadsPerCore=1
-{{ range .SlurmdNodes -}}
-NodeName={{ $slikName }}-{{ .NodeName }} CPUs={{ .CPUs }} RealMemory={{ .RealMemory }} ThreadsPerCore={{ .ThreadsPerCore }}
-{{ end }}
-
-# TODO other?
-PartitionName=DEFAULT Nodes=ALL MaxTime=60 State=UP
-PartitionName=batch Nodes=ALL Default=YES MaxTime=60 State=Up
-#PartitionName=debug Nodes=ALL Default=YES MaxTime=INFINITE State=UP
+[domain/LDAP]
+id_provider = ldap
+auth_provider = ldap
+ldap_uri = {{ .URI }}
+ldap_search_base = {{ .BaseDN }}
+{{- if .BindDN }}
+ldap_default_bind_dn = {{ .BindDN }}
+ldap_default_authtok_type = password
+ldap_default_authtok = {{ .BindPassword }}
+{{- end }}
+{{- if .CACert }}
+ldap_tls_cacert = /etc/sssd/certs/ca.crt
+ldap_tls_reqcert = demand
+{{- end }}
+cache_credentials = true
+enumerate = false
 `
 
-	slurmdbdConfTpl = `
-{{ $slikName := .SlikName }}
-AuthType=auth/munge
-
-DbdHost={{ $slikName }}-slurmdbd
-DbdPort=6819
-
-DebugLevel=verbose
-MessageTimeout=10
-
-StorageHost={{ $slikName }}-mariadb
-StorageLoc=slurmdbd
-StorageUser={{ .User }}
-StoragePass={{ .Pass }}
-StoragePort=3306
-
-StorageType=accounting_storage/mysql
-
-LogFile=/var/log/slurm/slurmdbd.log
-PidFile=/run/slurmdbd.pid
-SlurmUser=root
+	nsswitchSSSConf = `
+passwd:         files sss
+group:          files sss
+shadow:         files sss
+hosts:          files dns
+networks:       files
+protocols:      db files
+services:       db files
+ethers:         db files
+rpc:            db files
+netgroup:       nis sss
 `
 
 	slurmInit = `
@@ -0,0 +1,93 @@
+package slurm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurmconf"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// topologyUnknown switch of nodes missing a topology label or without kubernetes node
+	topologyUnknown = "unknown"
+	// topologySeparator joins the label values of a switch name, slurmName never outputs it
+	topologySeparator = "/"
+	// topologyRoot suffix of the cluster name for the root switch above the top level switches
+	topologyRoot = "-root"
+)
+
+func topologyEnabled(wl *v1s.Slik) bool {
+	return len(wl.Spec.Topology.Labels) > 0
+}
+
+// topologySwitches builds the switch tree from the spec.topology labels of the nodes, sorted by name. Switches
+// are named by the label values down to their level, e.g. ewr-1/rack-3. other are slurm nodes without
+// kubernetes node, like compute pool and elastic nodes, they are placed below unknown switches.
+func topologySwitches(wl *v1s.Slik, nodes []corev1.Node, other []string) []slurmconf.Switch {
+	levels := len(wl.Spec.Topology.Labels)
+	switches := map[string]*slurmconf.Switch{}
+	top := map[string]bool{}
+
+	add := func(node string, values []string) {
+		parent := ""
+		for level := range levels {
+			name := strings.Join(values[:level+1], topologySeparator)
+
+			sw, ok := switches[name]
+			if !ok {
+				sw = &slurmconf.Switch{Name: name}
+				switches[name] = sw
+			}
+
+			if level == 0 {
+				top[name] = true
+			} else if !slices.Contains(switches[parent].Switches, name) {
+				switches[parent].Switches = append(switches[parent].Switches, name)
+			}
+
+			parent = name
+		}
+
+		switches[parent].Nodes = append(switches[parent].Nodes, node)
+	}
+
+	for i := range nodes {
+		values := []string{}
+		for _, key := range wl.Spec.Topology.Labels {
+			value := slurmName(nodes[i].GetLabels()[key])
+			if value == "" {
+				value = topologyUnknown
+			}
+
+			values = append(values, value)
+		}
+
+		add(fmt.Sprintf("%s-%s", wl.Name, nodes[i].Name), values)
+	}
+
+	for _, node := range other {
+		add(node, slices.Repeat([]string{topologyUnknown}, levels))
+	}
+
+	// a root above the top level switches, otherwise jobs can not span them
+	if len(top) > 1 {
+		root := wl.Name + topologyRoot
+		switches[root] = &slurmconf.Switch{Name: root, Switches: slices.Collect(maps.Keys(top))}
+	}
+
+	result := []slurmconf.Switch{}
+	for _, name := range slices.Sorted(maps.Keys(switches)) {
+		sw := switches[name]
+		slices.Sort(sw.Switches)
+		slices.Sort(sw.Nodes)
+
+		result = append(result, *sw)
+	}
+
+	return result
+}
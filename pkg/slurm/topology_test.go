@@ -0,0 +1,141 @@
+package slurm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func rackNode(name, zone, rack string) *corev1.Node {
+	node := slurmLabeledNode(name, false)
+	if zone != "" {
+		node.Labels["topology.kubernetes.io/zone"] = zone
+	}
+
+	if rack != "" {
+		node.Labels["example.com/rack"] = rack
+	}
+
+	return node
+}
+
+func TestTopologyConf(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		rackNode("a", "ewr-1", "r1"),
+		rackNode("b", "ewr-1", "r1"),
+		rackNode("c", "ewr-1", "r2"),
+		rackNode("d", "ewr-2", "r1"),
+		rackNode("e", "", ""),
+	)
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Topology: v1s.Topology{Labels: []string{"topology.kubernetes.io/zone", "example.com/rack"}},
+			Elastic:  v1s.Elastic{Nodes: 1, CPUs: 1, RealMemory: 1024, ThreadsPerCore: 1, SuspendTime: 1, ResumeTimeout: 1},
+		},
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(cm.Data["slurm.conf"], "\nTopologyPlugin=topology/tree\n") {
+		t.Fatalf("expected topology plugin in slurm.conf:\n%s", cm.Data["slurm.conf"])
+	}
+
+	want := "\n" +
+		"SwitchName=ewr-1 Switches=ewr-1/r1,ewr-1/r2\n" +
+		"SwitchName=ewr-1/r1 Nodes=test-a,test-b\n" +
+		"SwitchName=ewr-1/r2 Nodes=test-c\n" +
+		"SwitchName=ewr-2 Switches=ewr-2/r1\n" +
+		"SwitchName=ewr-2/r1 Nodes=test-d\n" +
+		"SwitchName=test-root Switches=ewr-1,ewr-2,unknown\n" +
+		"SwitchName=unknown Switches=unknown/unknown\n" +
+		"SwitchName=unknown/unknown Nodes=test-e,test-elastic-0\n"
+	if cm.Data["topology.conf"] != want {
+		t.Fatalf("unexpected topology.conf:\n%s", cm.Data["topology.conf"])
+	}
+
+	// nodes coming and going change topology.conf without a restart of the pods
+	config.GetConfig().Slurm.Version = LiveNodesMinSlurmVersion
+	defer func() { config.GetConfig().Slurm.Version = "" }()
+
+	before := slurmConfChecksumAnnotations(client, wl)
+
+	if _, err := client.CoreV1().Nodes().Create(t.Context(), rackNode("f", "ewr-3", "r1"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err = GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(cm.Data["topology.conf"], "SwitchName=ewr-3/r1 Nodes=test-f\n") {
+		t.Fatalf("expected new node in topology.conf:\n%s", cm.Data["topology.conf"])
+	}
+
+	if after := slurmConfChecksumAnnotations(client, wl); after["slik.vultr.com/checksum-test-slurm"] != before["slik.vultr.com/checksum-test-slurm"] {
+		t.Fatal("expected topology changes not to restart the pods")
+	}
+}
+
+func TestNoTopologyByDefault(t *testing.T) {
+	client := fake.NewSimpleClientset(rackNode("a", "ewr-1", "r1"))
+	wl := &v1s.Slik{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	if err := buildSlurmconfConfigMap(client, wl); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := GetConfigMap(client, "test-slurm", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cm.Data["topology.conf"]; ok || strings.Contains(cm.Data["slurm.conf"], "TopologyPlugin") {
+		t.Fatalf("expected no topology: %+v", cm.Data)
+	}
+}
+
+func TestTopologySwitchNamesDoNotCollide(t *testing.T) {
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Topology: v1s.Topology{Labels: []string{"topology.kubernetes.io/zone", "example.com/rack"}},
+		},
+	}
+
+	// a zone named after the cluster, and values joining to the same name with _
+	switches := topologySwitches(wl, []corev1.Node{
+		*rackNode("a", "a_b", "c"),
+		*rackNode("b", "a", "b_c"),
+		*rackNode("c", "test", "r1"),
+	}, nil)
+
+	names := []string{}
+	for _, sw := range switches {
+		names = append(names, sw.Name)
+	}
+
+	want := []string{"a", "a/b_c", "a_b", "a_b/c", "test", "test-root", "test/r1"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("expected switches %v, got %v", want, names)
+	}
+}
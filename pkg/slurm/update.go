@@ -8,8 +8,11 @@ import (
 	"sort"
 	"time"
 
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,35 +45,53 @@ func configChecksumAnnotations(client kubernetes.Interface, namespace string, na
 			continue
 		}
 
-		h := sha256.New()
-		keys := make([]string, 0, len(cm.Data))
-		for key := range cm.Data {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-
-		for _, key := range keys {
-			h.Write([]byte(key))
-			h.Write([]byte(cm.Data[key]))
-		}
+		annotations[fmt.Sprintf("slik.vultr.com/checksum-%s", name)] = checksumData(cm.Data, cm.BinaryData)
+	}
 
-		binaryKeys := make([]string, 0, len(cm.BinaryData))
-		for key := range cm.BinaryData {
-			binaryKeys = append(binaryKeys, key)
-		}
-		sort.Strings(binaryKeys)
+	return annotations
+}
 
-		for _, key := range binaryKeys {
-			h.Write([]byte(key))
-			h.Write(cm.BinaryData[key])
+func secretChecksumAnnotations(client kubernetes.Interface, namespace string, names ...string) map[string]string {
+	annotations := map[string]string{}
+	for _, name := range names {
+		secret, err := GetSecret(client, name, namespace)
+		if err != nil {
+			continue
 		}
 
-		annotations[fmt.Sprintf("slik.vultr.com/checksum-%s", name)] = hex.EncodeToString(h.Sum(nil))
+		annotations[fmt.Sprintf("slik.vultr.com/checksum-%s", name)] = checksumData(nil, secret.Data)
 	}
 
 	return annotations
 }
 
+func checksumData(data map[string]string, binaryData map[string][]byte) string {
+	h := sha256.New()
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte(data[key]))
+	}
+
+	binaryKeys := make([]string, 0, len(binaryData))
+	for key := range binaryData {
+		binaryKeys = append(binaryKeys, key)
+	}
+	sort.Strings(binaryKeys)
+
+	for _, key := range binaryKeys {
+		h.Write([]byte(key))
+		h.Write(binaryData[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func applyConfigMap(client kubernetes.Interface, desired *v1.ConfigMap) error {
 	cm := client.CoreV1().ConfigMaps(desired.Namespace)
 	existing, err := cm.Get(context.TODO(), desired.Name, metav1.GetOptions{})
@@ -92,6 +113,27 @@ func applyConfigMap(client kubernetes.Interface, desired *v1.ConfigMap) error {
 	return err
 }
 
+func applySecret(client kubernetes.Interface, desired *v1.Secret) error {
+	secrets := client.CoreV1().Secrets(desired.Namespace)
+	existing, err := secrets.Get(context.TODO(), desired.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = secrets.Create(context.TODO(), desired, metav1.CreateOptions{})
+			return err
+		}
+
+		return err
+	}
+
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	existing.Type = desired.Type
+	existing.Data = desired.Data
+	_, err = secrets.Update(context.TODO(), existing, metav1.UpdateOptions{})
+
+	return err
+}
+
 func applyDeployment(client kubernetes.Interface, desired *appsv1.Deployment) error {
 	dep := client.AppsV1().Deployments(desired.Namespace)
 	existing, err := dep.Get(context.TODO(), desired.Name, metav1.GetOptions{})
@@ -179,6 +221,26 @@ func applyStatefulSet(client kubernetes.Interface, desired *appsv1.StatefulSet)
 	return err
 }
 
+func applyPodDisruptionBudget(client kubernetes.Interface, desired *policyv1.PodDisruptionBudget) error {
+	pdb := client.PolicyV1().PodDisruptionBudgets(desired.Namespace)
+	existing, err := pdb.Get(context.TODO(), desired.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = pdb.Create(context.TODO(), desired, metav1.CreateOptions{})
+			return err
+		}
+
+		return err
+	}
+
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	existing.Spec = desired.Spec
+	_, err = pdb.Update(context.TODO(), existing, metav1.UpdateOptions{})
+
+	return err
+}
+
 func waitForDeploymentAvailable(client kubernetes.Interface, namespace, name string) error {
 	deadline := time.Now().Add(time.Duration(SlurmablerWaitTimeoutSec) * time.Second)
 	for {
@@ -229,8 +291,35 @@ func updatePVCStorage(client kubernetes.Interface, name, namespace, storageSize
 	return err
 }
 
-func reconcileDisabledComponents(client kubernetes.Interface, name, namespace string, slurmdbd, slurmrestd bool) error {
-	if !slurmrestd {
+func reconcileDisabledComponents(client kubernetes.Interface, wl *v1s.Slik) error {
+	name := wl.Name
+	namespace := wl.Namespace
+
+	if err := reconcileSlurmctldMode(client, name, namespace, wl.Spec.Slurmctld.HighAvailability); err != nil {
+		return err
+	}
+
+	if wl.Spec.Identity.Mode != v1s.IdentityModeLDAP {
+		if err := SecretDelete(client, fmt.Sprintf("%s-sssd", name), namespace); err != nil {
+			return err
+		}
+
+		if err := ConfigMapDelete(client, fmt.Sprintf("%s-identity", name), namespace); err != nil {
+			return err
+		}
+	}
+
+	if !wl.Spec.Login.Enabled {
+		if err := DeploymentDelete(client, fmt.Sprintf("%s-login", name), namespace); err != nil {
+			return err
+		}
+
+		if err := ServiceDelete(client, fmt.Sprintf("%s-login", name), namespace); err != nil {
+			return err
+		}
+	}
+
+	if !wl.Spec.Slurmrestd {
 		if err := DeploymentDelete(client, fmt.Sprintf("%s-slurmrestd", name), namespace); err != nil {
 			return err
 		}
@@ -240,7 +329,7 @@ func reconcileDisabledComponents(client kubernetes.Interface, name, namespace st
 		}
 	}
 
-	if slurmdbd {
+	if wl.Spec.Slurmdbd {
 		return nil
 	}
 
@@ -272,3 +361,25 @@ func reconcileDisabledComponents(client kubernetes.Interface, name, namespace st
 
 	return nil
 }
+
+// reconcileSlurmctldMode removes the slurmctld workload of the mode that is not in use
+func reconcileSlurmctldMode(client kubernetes.Interface, name, namespace string, highAvailability bool) error {
+	if highAvailability {
+		return DeploymentDelete(client, fmt.Sprintf("%s-slurmctld", name), namespace)
+	}
+
+	if err := StatefulSetDelete(client, fmt.Sprintf("%s-slurmctld", name), namespace); err != nil {
+		return err
+	}
+
+	if err := ServiceDelete(client, fmt.Sprintf("%s-slurmctld-hosts", name), namespace); err != nil {
+		return err
+	}
+
+	// the single slurmctld keeps its state in the pod, pvc protection holds the claim until the StatefulSet is gone
+	if err := PersistentVolumeClaimDelete(client, fmt.Sprintf("%s-slurmctld-state", name), namespace); err != nil {
+		return err
+	}
+
+	return PodDisruptionBudgetDelete(client, fmt.Sprintf("%s-slurmctld", name), namespace)
+}
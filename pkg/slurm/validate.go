@@ -0,0 +1,182 @@
+package slurm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateSlik returns an error for each rule of the spec that is broken, rules needing the live cluster are
+// left to the reconciler checks
+func ValidateSlik(wl *v1s.Slik) error {
+	errs := []error{}
+
+	errs = append(errs, validateNames(wl)...)
+
+	if wl.Spec.Slurmrestd && !wl.Spec.Slurmdbd {
+		errs = append(errs, fmt.Errorf("%w: spec.slurmrestd requires spec.slurmdbd", ErrConflictingComponents))
+	}
+
+	errs = append(errs, ValidateStorage(wl), ValidateSpec(wl))
+
+	return errors.Join(errs...)
+}
+
+// ValidateStorage returns an error if a claim size of the spec is not valid. The reconciler checks it and
+// ValidateSpec on every loop, the names and components are left to the admission webhook so running clusters
+// are not failed by them.
+func ValidateStorage(wl *v1s.Slik) error {
+	errs := []error{}
+
+	if wl.Spec.Slurmdbd {
+		if err := validateStorageSize("spec.mariadb.storage_size", wl.Spec.MariaDB.StorageSize, MariaDBMinStorageSize); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if wl.Spec.Slurmctld.HighAvailability && wl.Spec.Slurmctld.StateStorageSize != "" {
+		if err := validateStorageSize("spec.slurmctld.stateStorageSize", wl.Spec.Slurmctld.StateStorageSize, "1"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateSlikUpdate validates wl like ValidateSlik and rejects changes to fields that are fixed once the
+// resources exist: the namespace, the storage classes and shrinking a claim. Errors old already had are
+// ignored, so a Slik created before a rule can still be updated.
+func ValidateSlikUpdate(old, wl *v1s.Slik) error {
+	errs := newErrors(ValidateSlik(wl), ValidateSlik(old))
+
+	for _, field := range []struct {
+		path     string
+		old, new string
+	}{
+		{"spec.namespace", specNamespace(old), specNamespace(wl)},
+		{"spec.mariadb.storage_class", old.Spec.MariaDB.StorageClass, wl.Spec.MariaDB.StorageClass},
+		{"spec.slurmctld.stateStorageClass", old.Spec.Slurmctld.StateStorageClass, wl.Spec.Slurmctld.StateStorageClass},
+	} {
+		if field.old != field.new {
+			errs = append(errs, fmt.Errorf("%w: %s can not change from %q to %q", ErrImmutableField, field.path, field.old, field.new))
+		}
+	}
+
+	if old.Spec.Slurmdbd && wl.Spec.Slurmdbd {
+		if err := validateStorageSize("spec.mariadb.storage_size", wl.Spec.MariaDB.StorageSize, old.Spec.MariaDB.StorageSize); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if old.Spec.Slurmctld.HighAvailability && wl.Spec.Slurmctld.HighAvailability {
+		if err := validateStorageSize("spec.slurmctld.stateStorageSize",
+			stateStorageSize(wl), stateStorageSize(old)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// newErrors returns the errors joined in err that are not in old
+func newErrors(err, old error) []error {
+	seen := map[string]bool{}
+	for _, e := range joinedErrors(old) {
+		seen[e.Error()] = true
+	}
+
+	errs := []error{}
+	for _, e := range joinedErrors(err) {
+		if !seen[e.Error()] {
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
+
+// joinedErrors flattens the errors of errors.Join
+func joinedErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	errs := []error{}
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, joinedErrors(e)...)
+	}
+
+	return errs
+}
+
+// validateNames returns an error if a Service, label or StatefulSet named after the Slik would be invalid
+func validateNames(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	names := []string{}
+
+	// the longest Service name, Services must be DNS-1035 labels
+	if wl.Spec.Slurmctld.HighAvailability {
+		hosts := fmt.Sprintf("%s-slurmctld-hosts", wl.Name)
+		if msgs := validation.IsDNS1035Label(hosts); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%w: metadata.name %q derives Service %s: %s", ErrNameTooLong, wl.Name, hosts, strings.Join(msgs, ", ")))
+		}
+
+		names = append(names, fmt.Sprintf("%s-slurmctld", wl.Name))
+	}
+
+	if wl.Spec.Slurmdbd {
+		names = append(names, fmt.Sprintf("%s-mariadb", wl.Name))
+	}
+
+	for i := range wl.Spec.ComputePools {
+		names = append(names, computePoolName(wl, &wl.Spec.ComputePools[i]))
+	}
+
+	for _, name := range names {
+		if len(name) > MaxStatefulSetNameLength {
+			errs = append(errs, fmt.Errorf("%w: StatefulSet %s must be no more than %d characters", ErrNameTooLong, name, MaxStatefulSetNameLength))
+		}
+	}
+
+	return errs
+}
+
+// validateStorageSize returns an error if size is not a quantity or is less than minimum
+func validateStorageSize(path, size, minimum string) error {
+	q, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("%w: %s %q: %s", ErrInvalidStorageSize, path, size, err)
+	}
+
+	m, err := resource.ParseQuantity(minimum)
+	if err != nil {
+		// the previous size was not valid, any valid size is an improvement
+		return nil
+	}
+
+	if q.Cmp(m) < 0 {
+		return fmt.Errorf("%w: %s %s must be at least %s", ErrInvalidStorageSize, path, size, minimum)
+	}
+
+	return nil
+}
+
+// specNamespace returns spec.namespace, which is defaulted to the namespace of the Slik
+func specNamespace(wl *v1s.Slik) string {
+	if wl.Spec.Namespace == "" {
+		return wl.Namespace
+	}
+
+	return wl.Spec.Namespace
+}
@@ -0,0 +1,265 @@
+package slurm
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/vultr/slik/cmd/slik/config"
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateSpec returns an error for each rule of the spec sections that is broken. It is part of ValidateSlik,
+// the reconciler checks it too for a Slik the admission webhook did not see.
+func ValidateSpec(wl *v1s.Slik) error {
+	errs := []error{}
+
+	errs = append(errs, validateSharedVolumeSpec(wl)...)
+	errs = append(errs, validateLogin(wl)...)
+	errs = append(errs, validateIdentity(wl)...)
+	errs = append(errs, validateElastic(wl)...)
+	errs = append(errs, validateAutoscaling(wl)...)
+	errs = append(errs, validateComputePools(wl)...)
+	errs = append(errs, validateLabelKeys("spec.nodeFeatures", wl.Spec.NodeFeatures)...)
+	errs = append(errs, validateLabelKeys("spec.topology.labels", wl.Spec.Topology.Labels)...)
+	errs = append(errs, validateCgroups(wl)...)
+
+	if err := ValidateSlurmConf(&wl.Spec.SlurmConf); err != nil {
+		errs = append(errs, fmt.Errorf("spec.slurmConf: %w", err))
+	}
+
+	if err := ValidateSlurmdbdConf(&wl.Spec.SlurmdbdConf); err != nil {
+		errs = append(errs, fmt.Errorf("spec.slurmdbdConf: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateSharedVolumeSpec returns an error for each spec.sharedVolumes entry that is not valid, the claims
+// are checked against the cluster by validateSharedVolumes
+func validateSharedVolumeSpec(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	names := map[string]bool{}
+	for i := range wl.Spec.SharedVolumes {
+		sv := &wl.Spec.SharedVolumes[i]
+
+		if msgs := validation.IsDNS1123Label(sv.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%w: spec.sharedVolumes name %q: %s", ErrInvalidSpec, sv.Name, strings.Join(msgs, ", ")))
+		}
+
+		if names[sv.Name] {
+			errs = append(errs, fmt.Errorf("%w: spec.sharedVolumes name %q is used more than once", ErrInvalidSpec, sv.Name))
+		}
+
+		names[sv.Name] = true
+
+		if !path.IsAbs(sv.MountPath) || slices.Contains(ReservedMountPaths, path.Clean(sv.MountPath)) {
+			errs = append(errs, fmt.Errorf("%w: spec.sharedVolumes %s mountPath %q must be absolute and not one of %v",
+				ErrInvalidSpec, sv.Name, sv.MountPath, ReservedMountPaths))
+		}
+
+		sources := 0
+		for _, set := range []bool{sv.ClaimName != "", sv.NFS != nil, sv.HostPath != nil} {
+			if set {
+				sources++
+			}
+		}
+
+		if sources != 1 {
+			errs = append(errs, fmt.Errorf("%w: spec.sharedVolumes %s must set exactly one of claimName, nfs or hostPath",
+				ErrInvalidSpec, sv.Name))
+		}
+
+		for _, component := range sv.Components {
+			switch component {
+			case v1s.ComponentSlurmd, v1s.ComponentSlurmctld, v1s.ComponentSlurmrestd, v1s.ComponentToolbox, v1s.ComponentLogin:
+				// no-op
+			default:
+				errs = append(errs, fmt.Errorf("%w: spec.sharedVolumes %s component %q", ErrInvalidSpec, sv.Name, component))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateLogin returns an error for each rule of spec.login that is broken
+func validateLogin(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	if !wl.Spec.Login.Enabled {
+		return errs
+	}
+
+	if wl.Spec.Login.Replicas < 0 {
+		errs = append(errs, fmt.Errorf("%w: spec.login.replicas must not be negative", ErrInvalidSpec))
+	}
+
+	switch wl.Spec.Login.ServiceType {
+	case "", corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort, corev1.ServiceTypeClusterIP:
+		// no-op
+	default:
+		errs = append(errs, fmt.Errorf("%w: spec.login.serviceType %s", ErrInvalidSpec, wl.Spec.Login.ServiceType))
+	}
+
+	// login pods do not create accounts, the users must resolve to the same UID and GID in every pod
+	if len(wl.Spec.Login.AuthorizedKeys) > 0 && wl.Spec.Identity.Mode == v1s.IdentityModeNone {
+		errs = append(errs, fmt.Errorf("%w: spec.login.authorizedKeys requires spec.identity", ErrInvalidSpec))
+	}
+
+	users := map[string]bool{}
+	for i := range wl.Spec.Login.AuthorizedKeys {
+		ak := &wl.Spec.Login.AuthorizedKeys[i]
+
+		if !LoginUserRegexp.MatchString(ak.User) {
+			errs = append(errs, fmt.Errorf("%w: spec.login.authorizedKeys user %q is not a valid user name", ErrInvalidSpec, ak.User))
+		}
+
+		if users[ak.User] {
+			errs = append(errs, fmt.Errorf("%w: spec.login.authorizedKeys user %q is used more than once", ErrInvalidSpec, ak.User))
+		}
+
+		users[ak.User] = true
+
+		if (ak.ConfigMapKeyRef == nil) == (ak.SecretKeyRef == nil) {
+			errs = append(errs, fmt.Errorf("%w: spec.login.authorizedKeys %s must set exactly one of configMapKeyRef or secretKeyRef",
+				ErrInvalidSpec, ak.User))
+		}
+	}
+
+	return errs
+}
+
+// validateIdentity returns an error if spec.identity is incomplete
+func validateIdentity(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	switch wl.Spec.Identity.Mode {
+	case v1s.IdentityModeNone:
+		// no-op
+	case v1s.IdentityModeLDAP:
+		ldap := &wl.Spec.Identity.LDAP
+
+		if ldap.URI == "" || ldap.BaseDN == "" {
+			errs = append(errs, fmt.Errorf("%w: spec.identity.ldap requires uri and baseDN", ErrInvalidSpec))
+		}
+
+		if (ldap.BindDN == "") != (ldap.BindPasswordSecretRef == nil) {
+			errs = append(errs, fmt.Errorf("%w: spec.identity.ldap bindDN and bindPasswordSecretRef must be set together", ErrInvalidSpec))
+		}
+	case v1s.IdentityModeLocal:
+		if wl.Spec.Identity.Local.ConfigMap == "" {
+			errs = append(errs, fmt.Errorf("%w: spec.identity.local requires configMap", ErrInvalidSpec))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%w: spec.identity.mode %s", ErrInvalidSpec, wl.Spec.Identity.Mode))
+	}
+
+	return errs
+}
+
+// validateElastic returns an error if elastic nodes are enabled without their shape or power saving times
+func validateElastic(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	elastic := &wl.Spec.Elastic
+	if elastic.Nodes == 0 {
+		return errs
+	}
+
+	if elastic.CPUs <= 0 || elastic.RealMemory <= 0 {
+		errs = append(errs, fmt.Errorf("%w: spec.elastic nodes require cpus and realMemory", ErrInvalidSpec))
+	}
+
+	if elastic.ThreadsPerCore <= 0 || elastic.SuspendTime <= 0 || elastic.ResumeTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("%w: spec.elastic threadsPerCore, suspendTime and resumeTimeout must be positive", ErrInvalidSpec))
+	}
+
+	return errs
+}
+
+// validateAutoscaling returns an error if autoscaling is enabled without balloon resources
+func validateAutoscaling(wl *v1s.Slik) []error {
+	if wl.Spec.Autoscaling.Enabled && len(wl.Spec.Autoscaling.BalloonResources) == 0 {
+		return []error{fmt.Errorf("%w: spec.autoscaling.balloonResources must be sized to a node", ErrInvalidSpec)}
+	}
+
+	return nil
+}
+
+// validateComputePools returns an error for each spec.computePools entry that is not valid
+func validateComputePools(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	names := map[string]bool{}
+	for i := range wl.Spec.ComputePools {
+		pool := &wl.Spec.ComputePools[i]
+
+		// pool names are part of the statefulset, pod and slurm node names and a partition
+		msgs := validation.IsDNS1123Label(pool.Name)
+		if slices.Contains(ReservedComputePoolNames, pool.Name) {
+			msgs = append(msgs, fmt.Sprintf("%v are reserved", ReservedComputePoolNames))
+		}
+
+		if len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%w: spec.computePools name %q: %s", ErrInvalidSpec, pool.Name, strings.Join(msgs, ", ")))
+		}
+
+		if names[pool.Name] {
+			errs = append(errs, fmt.Errorf("%w: spec.computePools name %q is used more than once", ErrInvalidSpec, pool.Name))
+		}
+
+		names[pool.Name] = true
+
+		if pool.Replicas < 0 {
+			errs = append(errs, fmt.Errorf("%w: spec.computePools %s replicas must not be negative", ErrInvalidSpec, pool.Name))
+		}
+
+		cpu, memory := pool.Resources.Limits.Cpu(), pool.Resources.Limits.Memory()
+		if cpu.MilliValue() < 1000 || memory.IsZero() {
+			errs = append(errs, fmt.Errorf("%w: spec.computePools %s requires limits of at least 1 cpu and memory", ErrInvalidSpec, pool.Name))
+		}
+	}
+
+	return errs
+}
+
+// validateLabelKeys returns an error for each key that is not a valid label key
+func validateLabelKeys(field string, keys []string) []error {
+	errs := []error{}
+
+	for _, key := range keys {
+		if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%w: %s label %q: %s", ErrInvalidSpec, field, key, strings.Join(msgs, ", ")))
+		}
+	}
+
+	return errs
+}
+
+// validateCgroups returns an error if spec.cgroups is not supported by the Slurm of the images or the slurmd pods
+func validateCgroups(wl *v1s.Slik) []error {
+	errs := []error{}
+
+	if !wl.Spec.Cgroups.Enabled {
+		return errs
+	}
+
+	if !VersionAtLeast(CgroupsMinSlurmVersion) {
+		errs = append(errs, fmt.Errorf("%w: spec.cgroups require Slurm %s or later, the images run Slurm %s",
+			ErrInvalidSpec, CgroupsMinSlurmVersion, config.GetSlurmVersion()))
+	}
+
+	// slurmd mounts the cgroup hierarchy of the node, compute pool pods sharing a node would write the same tree
+	if len(wl.Spec.ComputePools) > 0 {
+		errs = append(errs, fmt.Errorf("%w: spec.cgroups can not be enabled with spec.computePools", ErrInvalidSpec))
+	}
+
+	return errs
+}
@@ -0,0 +1,165 @@
+package slurm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func validSlik() *v1s.Slik {
+	return &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Namespace:  "default",
+			Slurmdbd:   true,
+			Slurmrestd: true,
+			MariaDB:    v1s.MariaDB{StorageSize: "50G", StorageClass: "block"},
+			Slurmctld:  v1s.Slurmctld{HighAvailability: true, StateStorageSize: "2Gi", StateStorageClass: "rwx"},
+		},
+	}
+}
+
+func TestValidateSlik(t *testing.T) {
+	if err := ValidateSlik(validSlik()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		mutate func(*v1s.Slik)
+		err    error
+	}{
+		"slurmrestd without slurmdbd": {func(wl *v1s.Slik) { wl.Spec.Slurmdbd = false }, ErrConflictingComponents},
+		"mariadb not a quantity":      {func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageSize = "lots" }, ErrInvalidStorageSize},
+		"mariadb too small":           {func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageSize = "10G" }, ErrInvalidStorageSize},
+		"state not a quantity":        {func(wl *v1s.Slik) { wl.Spec.Slurmctld.StateStorageSize = "1 Gi" }, ErrInvalidStorageSize},
+		"name too long":               {func(wl *v1s.Slik) { wl.Name = strings.Repeat("a", 43) }, ErrNameTooLong},
+		"name not a dns label":        {func(wl *v1s.Slik) { wl.Name = "1test" }, ErrNameTooLong},
+		"pool name too long": {func(wl *v1s.Slik) {
+			wl.Spec.ComputePools = []v1s.ComputePool{{Name: strings.Repeat("p", 48)}}
+		}, ErrNameTooLong},
+		"owned slurm.conf key": {func(wl *v1s.Slik) {
+			wl.Spec.SlurmConf.Extra = []v1s.ConfParameter{{Key: "SlurmctldHost", Value: "elsewhere"}}
+		}, ErrOwnedConfKey},
+		"cgroups on slurm 21.08": {func(wl *v1s.Slik) { wl.Spec.Cgroups.Enabled = true }, ErrInvalidSpec},
+		"shared volume without source": {func(wl *v1s.Slik) {
+			wl.Spec.SharedVolumes = []v1s.SharedVolume{{Name: "home", MountPath: "/home"}}
+		}, ErrInvalidSpec},
+		"reserved mount path": {func(wl *v1s.Slik) {
+			wl.Spec.SharedVolumes = []v1s.SharedVolume{{Name: "conf", MountPath: "/etc/slurm/", ClaimName: "conf"}}
+		}, ErrInvalidSpec},
+		"login user not valid": {func(wl *v1s.Slik) {
+			wl.Spec.Login = v1s.Login{Enabled: true, AuthorizedKeys: []v1s.AuthorizedKeys{{User: "Root"}}}
+		}, ErrInvalidSpec},
+		"login keys without identity": {func(wl *v1s.Slik) {
+			wl.Spec.Login = v1s.Login{Enabled: true, AuthorizedKeys: []v1s.AuthorizedKeys{
+				{User: "alice", ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "authorized_keys"}},
+			}}
+		}, ErrInvalidSpec},
+		"ldap without uri": {func(wl *v1s.Slik) { wl.Spec.Identity.Mode = v1s.IdentityModeLDAP }, ErrInvalidSpec},
+		"topology label not valid": {func(wl *v1s.Slik) {
+			wl.Spec.Topology.Labels = []string{"example.com/rack/row"}
+		}, ErrInvalidSpec},
+		"reserved pool name": {func(wl *v1s.Slik) {
+			wl.Spec.ComputePools = []v1s.ComputePool{{Name: "batch"}}
+		}, ErrInvalidSpec},
+	} {
+		wl := validSlik()
+		tc.mutate(wl)
+
+		if err := ValidateSlik(wl); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.err, err)
+		}
+	}
+
+	// the longest name fitting the slurmctld StatefulSet
+	wl := validSlik()
+	wl.Name = strings.Repeat("a", 42)
+	if err := ValidateSlik(wl); err != nil {
+		t.Fatal(err)
+	}
+
+	// without high availability slurmctld is a Deployment, the mariadb StatefulSet is the longest name
+	wl.Name = strings.Repeat("a", 44)
+	wl.Spec.Slurmctld = v1s.Slurmctld{}
+	if err := ValidateSlik(wl); err != nil {
+		t.Fatal(err)
+	}
+
+	wl.Name = strings.Repeat("a", 52)
+	wl.Spec.Slurmdbd, wl.Spec.Slurmrestd = false, false
+	if err := ValidateSlik(wl); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateStorage(t *testing.T) {
+	// the rules added with the webhook do not fail a running cluster
+	wl := validSlik()
+	wl.Name = strings.Repeat("a", 50)
+	wl.Spec.Slurmdbd = false
+	if err := ValidateStorage(wl); err != nil {
+		t.Fatal(err)
+	}
+
+	wl.Spec.Slurmdbd = true
+	wl.Spec.MariaDB.StorageSize = "10G"
+	if err := ValidateStorage(wl); !errors.Is(err, ErrInvalidStorageSize) {
+		t.Fatalf("expected %v, got %v", ErrInvalidStorageSize, err)
+	}
+}
+
+func TestValidateSlikUpdate(t *testing.T) {
+	old := validSlik()
+
+	grown := validSlik()
+	grown.Spec.MariaDB.StorageSize = "100G"
+	grown.Spec.Login.Enabled = true
+	if err := ValidateSlikUpdate(old, grown); err != nil {
+		t.Fatal(err)
+	}
+
+	// a Slik created without spec.namespace is defaulted on its next update
+	unset := validSlik()
+	unset.Spec.Namespace = ""
+	if err := ValidateSlikUpdate(unset, validSlik()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		mutate func(*v1s.Slik)
+		err    error
+	}{
+		"namespace":           {func(wl *v1s.Slik) { wl.Spec.Namespace = "other" }, ErrImmutableField},
+		"mariadb class":       {func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageClass = "other" }, ErrImmutableField},
+		"state class":         {func(wl *v1s.Slik) { wl.Spec.Slurmctld.StateStorageClass = "" }, ErrImmutableField},
+		"mariadb shrinks":     {func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageSize = "45G" }, ErrInvalidStorageSize},
+		"state default below": {func(wl *v1s.Slik) { wl.Spec.Slurmctld.StateStorageSize = "" }, ErrInvalidStorageSize},
+		"spec not valid":      {func(wl *v1s.Slik) { wl.Spec.Slurmdbd = false }, ErrConflictingComponents},
+	} {
+		wl := validSlik()
+		tc.mutate(wl)
+
+		if err := ValidateSlikUpdate(old, wl); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.err, err)
+		}
+	}
+
+	// a Slik created before the webhook keeps its broken rules, new ones are rejected
+	legacy := validSlik()
+	legacy.Spec.Slurmdbd = false
+	updated := legacy.DeepCopy()
+	updated.Spec.Login.Enabled = true
+	if err := ValidateSlikUpdate(legacy, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	updated.Spec.ComputePools = []v1s.ComputePool{{Name: strings.Repeat("p", 48)}}
+	if err := ValidateSlikUpdate(legacy, updated); !errors.Is(err, ErrNameTooLong) {
+		t.Fatalf("expected %v, got %v", ErrNameTooLong, err)
+	}
+}
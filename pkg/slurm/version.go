@@ -0,0 +1,40 @@
+package slurm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vultr/slik/cmd/slik/config"
+)
+
+// VersionAtLeast returns true if the Slurm release of the images, slurm.version of the operator config,
+// is minimum or later
+func VersionAtLeast(minimum string) bool {
+	return compareVersions(config.GetSlurmVersion(), minimum) >= 0
+}
+
+// compareVersions compares two Slurm releases like 23.11 or 23.11.4, a part that is not a number is 0
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,36 @@
+package slurm
+
+import (
+	"testing"
+
+	"github.com/vultr/slik/cmd/slik/config"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"21.08", "23.02", -1},
+		{"23.02", "23.02", 0},
+		{"23.11.4", "23.11", 1},
+		{"24.05", "23.11", 1},
+		{"9.1", "23.02", -1},
+	} {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Fatalf("compareVersions(%s, %s) = %d, expected %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+
+	// the published images when not set
+	if VersionAtLeast(CgroupsMinSlurmVersion) {
+		t.Fatalf("expected Slurm %s not to support cgroups", config.GetSlurmVersion())
+	}
+
+	config.GetConfig().Slurm.Version = "23.11"
+	defer func() { config.GetConfig().Slurm.Version = "" }()
+
+	if !VersionAtLeast(CgroupsMinSlurmVersion) {
+		t.Fatal("expected Slurm 23.11 to support cgroups")
+	}
+}
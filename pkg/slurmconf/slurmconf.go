@@ -0,0 +1,156 @@
+// Package slurmconf renders the slurm configuration files from a typed model. Values are written as is,
+// text/template does not escape them, and a template referencing a missing field fails to render.
+package slurmconf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Slurm configuration for generation of slurm.conf and the files next to it
+type Slurm struct {
+	SlikName string
+
+	SlurmctldHosts []string
+	SlurmdNodes    []Node
+	Slurmdbd       bool
+	Configless     bool
+	Gres           bool
+
+	Cgroups *Cgroups
+
+	Elastic      *Elastic
+	ElasticNodes []Node
+
+	Partitions []Partition
+
+	// Maintenance sets every partition DOWN
+	Maintenance bool
+
+	Topology []Switch
+
+	// Scripts parameters of spec.scripts
+	Scripts []Parameter
+
+	// Extra parameters and Include files of spec.slurmConf
+	Extra    []Parameter
+	Includes []string
+}
+
+// Node for generation of the nodes section in slurm.conf
+type Node struct {
+	NodeName       string
+	CPUs           int
+	ThreadsPerCore int
+	RealMemory     int
+
+	// topology of nodes labeled by slurmabler, 0 if unknown
+	Boards          int
+	SocketsPerBoard int
+	CoresPerSocket  int
+
+	GPUs    int
+	GPUType string
+	// GPUCount lists the GPUs by count in gres.conf, the device plugin gave slurmd only some of the devices
+	// of the node and their files are not known
+	GPUCount bool
+
+	// Features for --constraint, from the spec.nodeFeatures labels of the kubernetes node
+	Features []string
+}
+
+// GPUFiles returns the device files of the node GPUs for gres.conf
+func (n Node) GPUFiles() string {
+	if n.GPUs == 1 {
+		return "/dev/nvidia0"
+	}
+
+	return fmt.Sprintf("/dev/nvidia[0-%d]", n.GPUs-1)
+}
+
+// Partition a partition of compute pool nodes
+type Partition struct {
+	Name  string
+	Nodes []string
+}
+
+// Switch a switch of topology.conf, above other switches or a leaf with nodes
+type Switch struct {
+	Name     string
+	Switches []string
+	Nodes    []string
+}
+
+// Cgroups the constraints of cgroup.conf
+type Cgroups struct {
+	ConstrainCores    bool
+	ConstrainRAMSpace bool
+	ConstrainDevices  bool
+}
+
+// Elastic power saving parameters, in seconds
+type Elastic struct {
+	SuspendTime   int32
+	ResumeTimeout int32
+}
+
+// Parameter a Key=Value line
+type Parameter struct {
+	Key   string
+	Value string
+}
+
+// Slurmdbd configuration for generation of slurmdbd.conf
+type Slurmdbd struct {
+	SlikName string
+
+	User string
+	Pass string
+
+	// Extra parameters and Include files of spec.slurmdbdConf
+	Extra    []Parameter
+	Includes []string
+}
+
+// RenderSlurmConf renders slurm.conf
+func RenderSlurmConf(conf *Slurm) (string, error) {
+	return render("slurm.conf", slurmConfTpl, conf)
+}
+
+// RenderGresConf renders gres.conf with the GPUs of the slurmd nodes
+func RenderGresConf(conf *Slurm) (string, error) {
+	return render("gres.conf", gresConfTpl, conf)
+}
+
+// RenderCgroupConf renders cgroup.conf, empty if cgroups are disabled
+func RenderCgroupConf(conf *Slurm) (string, error) {
+	return render("cgroup.conf", cgroupConfTpl, conf)
+}
+
+// RenderTopologyConf renders topology.conf
+func RenderTopologyConf(conf *Slurm) (string, error) {
+	return render("topology.conf", topologyConfTpl, conf)
+}
+
+// RenderSlurmdbdConf renders slurmdbd.conf
+func RenderSlurmdbdConf(conf *Slurmdbd) (string, error) {
+	return render("slurmdbd.conf", slurmdbdConfTpl, conf)
+}
+
+func render(name, text string, data any) (string, error) {
+	tpl, err := template.New(name).Funcs(
+		template.FuncMap{"StringsJoin": strings.Join},
+	).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,141 @@
+package slurmconf
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden compares got with testdata/name, or writes it with -update
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != string(want) {
+		t.Fatalf("%s differs from the golden file, rerun with -update if expected:\n%s", name, got)
+	}
+}
+
+func minimal() *Slurm {
+	return &Slurm{
+		SlikName:       "test",
+		SlurmctldHosts: []string{"test-slurmctld-0(test-slurmctld-0.test-slurmctld)"},
+		SlurmdNodes: []Node{
+			{NodeName: "node-a", CPUs: 4, ThreadsPerCore: 1, RealMemory: 7900},
+		},
+	}
+}
+
+func TestRenderSlurmConf(t *testing.T) {
+	full := minimal()
+	full.SlurmctldHosts = append(full.SlurmctldHosts, "test-slurmctld-1(test-slurmctld-1.test-slurmctld)")
+	full.Slurmdbd = true
+	full.Configless = true
+	full.Scripts = []Parameter{{Key: "Prolog", Value: "/etc/slik/scripts/prolog"}, {Key: "JobSubmitPlugins", Value: "lua"}}
+	full.Extra = []Parameter{{Key: "SchedulerParameters", Value: "bf_continue,bf_max_job_test=500"}}
+	full.Includes = []string{"include-site-qos.conf"}
+
+	heterogeneous := minimal()
+	heterogeneous.Gres = true
+	heterogeneous.Cgroups = &Cgroups{ConstrainCores: true, ConstrainRAMSpace: true}
+	heterogeneous.SlurmdNodes = append(heterogeneous.SlurmdNodes,
+		Node{
+			NodeName: "node-b", CPUs: 128, ThreadsPerCore: 2, RealMemory: 515000,
+			Boards: 1, SocketsPerBoard: 2, CoresPerSocket: 32,
+			GPUs: 8, GPUType: "a100", Features: []string{"ewr", "nvme"},
+		},
+		Node{NodeName: "node-c", CPUs: 16, ThreadsPerCore: 1, RealMemory: 64000, GPUs: 1},
+		Node{NodeName: "node-d", CPUs: 16, ThreadsPerCore: 1, RealMemory: 64000, GPUs: 2, GPUCount: true},
+	)
+	heterogeneous.Elastic = &Elastic{SuspendTime: 600, ResumeTimeout: 300}
+	heterogeneous.ElasticNodes = []Node{{NodeName: "elastic-0", CPUs: 8, ThreadsPerCore: 1, RealMemory: 30000}}
+	heterogeneous.Topology = []Switch{{Name: "ewr", Nodes: []string{"test-node-a", "test-node-b"}}}
+
+	partitions := minimal()
+	partitions.SlurmdNodes = append(partitions.SlurmdNodes,
+		Node{NodeName: "small-0", CPUs: 2, ThreadsPerCore: 1, RealMemory: 4096},
+		Node{NodeName: "small-1", CPUs: 2, ThreadsPerCore: 1, RealMemory: 4096},
+		Node{NodeName: "r&d-0", CPUs: 8, ThreadsPerCore: 1, RealMemory: 16384},
+	)
+	partitions.Partitions = []Partition{
+		{Name: "small", Nodes: []string{"test-small-0", "test-small-1"}},
+		{Name: "r&d", Nodes: []string{"test-r&d-0"}},
+	}
+
+	maintenance := *partitions
+	maintenance.Maintenance = true
+
+	for name, conf := range map[string]*Slurm{
+		"slurm.conf.nodbd.golden":         minimal(),
+		"slurm.conf.dbd.golden":           full,
+		"slurm.conf.heterogeneous.golden": heterogeneous,
+		"slurm.conf.partitions.golden":    partitions,
+		"slurm.conf.maintenance.golden":   &maintenance,
+	} {
+		got, err := RenderSlurmConf(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		golden(t, name, got)
+	}
+
+	for name, render := range map[string]func(*Slurm) (string, error){
+		"gres.conf.golden":     RenderGresConf,
+		"cgroup.conf.golden":   RenderCgroupConf,
+		"topology.conf.golden": RenderTopologyConf,
+	} {
+		got, err := render(heterogeneous)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		golden(t, name, got)
+	}
+}
+
+func TestRenderSlurmdbdConf(t *testing.T) {
+	got, err := RenderSlurmdbdConf(&Slurmdbd{
+		SlikName: "test",
+		User:     "slurm",
+		Pass:     `p&ss'w<rd>"`,
+		Extra:    []Parameter{{Key: "PurgeJobAfter", Value: "12months"}},
+		Includes: []string{"include-site-archive.conf"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "slurmdbd.conf.golden", got)
+
+	if !strings.Contains(got, "\nStoragePass=p&ss'w<rd>\"\n") {
+		t.Fatalf("expected the password unescaped:\n%s", got)
+	}
+}
+
+func TestRenderMissingKey(t *testing.T) {
+	if _, err := render("missing", "{{ .NotAField }}", minimal()); err == nil {
+		t.Fatal("expected a missing field to fail")
+	}
+
+	if _, err := render("missing", "{{ .NotAKey }}", map[string]string{}); err == nil {
+		t.Fatal("expected a missing key to fail")
+	}
+}
@@ -0,0 +1,153 @@
+package slurmconf
+
+var (
+	slurmConfTpl = `
+{{ $slikName := .SlikName }}
+ClusterName=cluster
+{{ range .SlurmctldHosts -}}
+SlurmctldHost={{ . }}
+{{ end -}}
+{{ if .Cgroups -}}
+ProctrackType=proctrack/cgroup
+TaskPlugin=task/cgroup,task/affinity
+{{ else -}}
+ProctrackType=proctrack/linuxproc
+TaskPlugin=task/none
+{{ end -}}
+ReturnToService=2
+SlurmctldPidFile=/run/slurmctld.pid
+SlurmdPidFile=/run/slurmd.pid
+SlurmdSpoolDir=/var/lib/slurm/slurmd
+StateSaveLocation=/var/lib/slurm/slurmctld
+SlurmUser=root
+SchedulerType=sched/backfill
+SelectType=select/cons_tres
+SelectTypeParameters=CR_Core_Memory
+{{ if .Gres -}}
+GresTypes=gpu
+{{ end -}}
+{{ if .Topology -}}
+TopologyPlugin=topology/tree
+{{ end -}}
+JobCompType=jobcomp/none
+JobAcctGatherType=jobacct_gather/none
+SlurmctldDebug=verbose
+SlurmctldLogFile=/var/log/slurm/slurmctld.log
+SlurmdDebug=verbose
+SlurmdLogFile=/var/log/slurm/slurmd.log
+{{ if .Configless -}}
+SlurmctldParameters=enable_configless
+{{ end -}}
+{{ if .Elastic -}}
+# power saving, elastic nodes are started and stopped through the slik operator
+ResumeProgram=/usr/local/bin/slik-resume
+SuspendProgram=/usr/local/bin/slik-suspend
+SuspendTime={{ .Elastic.SuspendTime }}
+ResumeTimeout={{ .Elastic.ResumeTimeout }}
+CommunicationParameters=NoAddrCache
+{{ end -}}
+{{ range .Scripts -}}
+{{ .Key }}={{ .Value }}
+{{ end -}}
+
+# slurmdbd
+{{ if .Slurmdbd -}}
+AccountingStorageType=accounting_storage/slurmdbd
+AccountingStoragePort=6819
+AccountingStorageHost={{ $slikName }}-slurmdbd
+{{ else }}
+AccountingStorageType=accounting_storage/none
+{{ end }}
+{{ if or .Extra .Includes -}}
+# spec.slurmConf
+{{ range .Extra -}}
+{{ .Key }}={{ .Value }}
+{{ end -}}
+{{ range .Includes -}}
+Include /etc/slurm/{{ . }}
+{{ end }}
+{{ end -}}
+
+# nodes
+NodeName=DEFAULT State=UNKNOWN
+{{ range .SlurmdNodes -}}
+NodeName={{ $slikName }}-{{ .NodeName }} NodeAddr={{ $slikName }}-{{ .NodeName }}.{{ $slikName }}-slurmd CPUs={{ .CPUs }} RealMemory={{ .RealMemory }}{{ if .CoresPerSocket }} Boards={{ .Boards }} SocketsPerBoard={{ .SocketsPerBoard }} CoresPerSocket={{ .CoresPerSocket }}{{ end }} ThreadsPerCore={{ .ThreadsPerCore }}{{ if .GPUs }} Gres=gpu:{{ if .GPUType }}{{ .GPUType }}:{{ end }}{{ .GPUs }}{{ end }}{{ if .Features }} Features={{ StringsJoin .Features "," }}{{ end }}
+{{ end -}}
+{{ range .ElasticNodes -}}
+NodeName={{ $slikName }}-{{ .NodeName }} NodeAddr={{ $slikName }}-{{ .NodeName }}.{{ $slikName }}-slurmd State=CLOUD CPUs={{ .CPUs }} RealMemory={{ .RealMemory }} ThreadsPerCore={{ .ThreadsPerCore }}
+{{ end }}
+
+# TODO other?
+{{ if .Maintenance -}}
+# spec.maintenance
+PartitionName=DEFAULT Nodes=ALL MaxTime=60 State=DOWN
+PartitionName=batch Nodes=ALL Default=YES MaxTime=60 State=DOWN
+{{ else -}}
+PartitionName=DEFAULT Nodes=ALL MaxTime=60 State=UP
+PartitionName=batch Nodes=ALL Default=YES MaxTime=60 State=Up
+{{ end -}}
+{{ $state := "UP" }}{{ if .Maintenance }}{{ $state = "DOWN" }}{{ end -}}
+{{ range .Partitions -}}
+PartitionName={{ .Name }} Nodes={{ StringsJoin .Nodes "," }} MaxTime=60 State={{ $state }}
+{{ end -}}
+#PartitionName=debug Nodes=ALL Default=YES MaxTime=INFINITE State=UP
+`
+
+	gresConfTpl = `
+{{ $slikName := .SlikName -}}
+{{ range .SlurmdNodes -}}
+{{ if .GPUs -}}
+NodeName={{ $slikName }}-{{ .NodeName }} Name=gpu{{ if .GPUType }} Type={{ .GPUType }}{{ end }} {{ if .GPUCount }}Count={{ .GPUs }}{{ else }}File={{ .GPUFiles }}{{ end }}
+{{ end -}}
+{{ end -}}
+`
+
+	cgroupConfTpl = `
+{{ with .Cgroups -}}
+CgroupPlugin=autodetect
+# slurmd runs in a container without systemd
+IgnoreSystemd=yes
+ConstrainCores={{ if .ConstrainCores }}yes{{ else }}no{{ end }}
+ConstrainRAMSpace={{ if .ConstrainRAMSpace }}yes{{ else }}no{{ end }}
+ConstrainDevices={{ if .ConstrainDevices }}yes{{ else }}no{{ end }}
+{{ end -}}
+`
+
+	topologyConfTpl = `
+{{ range .Topology -}}
+SwitchName={{ .Name }}{{ if .Switches }} Switches={{ StringsJoin .Switches "," }}{{ end }}{{ if .Nodes }} Nodes={{ StringsJoin .Nodes "," }}{{ end }}
+{{ end -}}
+`
+
+	slurmdbdConfTpl = `
+{{ $slikName := .SlikName }}
+AuthType=auth/munge
+
+DbdHost={{ $slikName }}-slurmdbd
+DbdPort=6819
+
+DebugLevel=verbose
+MessageTimeout=10
+
+StorageHost={{ $slikName }}-mariadb
+StorageLoc=slurmdbd
+StorageUser={{ .User }}
+StoragePass={{ .Pass }}
+StoragePort=3306
+
+StorageType=accounting_storage/mysql
+
+LogFile=/var/log/slurm/slurmdbd.log
+PidFile=/run/slurmdbd.pid
+SlurmUser=root
+{{ if or .Extra .Includes }}
+# spec.slurmdbdConf
+{{ range .Extra -}}
+{{ .Key }}={{ .Value }}
+{{ end -}}
+{{ range .Includes -}}
+Include /etc/slurm/{{ . }}
+{{ end -}}
+{{ end -}}
+`
+)
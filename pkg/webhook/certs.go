@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EnsureCertificates returns the serving certificate of the webhook for service in namespace. The CA and the
+// certificate are kept in the SecretName Secret and created or renewed as needed, the CA is then set as
+// caBundle of the webhook configurations.
+func EnsureCertificates(client kubernetes.Interface, namespace, service string) (*tls.Certificate, error) {
+	log := zap.L().Sugar()
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), SecretName, metav1.GetOptions{})
+	exists := err == nil
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      SecretName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app":                          "slik-operator",
+					"app.kubernetes.io/managed-by": "slik",
+				},
+			},
+			Type: v1.SecretTypeTLS,
+		}
+	}
+
+	dnsNames := serviceDNSNames(namespace, service)
+
+	cert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+	if err != nil || !certValid(cert.Leaf, dnsNames) {
+		log.Infof("issuing webhook certificate for %v", dnsNames)
+
+		if err := issueCertificates(secret, dnsNames); err != nil {
+			return nil, err
+		}
+
+		if exists {
+			secret, err = client.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		} else {
+			secret, err = client.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if cert, err = tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setCABundle(client, secret.Data[SecretKeyCACert]); err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+func serviceDNSNames(namespace, service string) []string {
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+	}
+}
+
+// certValid returns true if cert is valid for every name for longer than CertRenewBefore
+func certValid(cert *x509.Certificate, dnsNames []string) bool {
+	if cert == nil || time.Until(cert.NotAfter) < CertRenewBefore {
+		return false
+	}
+
+	for _, name := range dnsNames {
+		if !slices.Contains(cert.DNSNames, name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// issueCertificates sets a serving certificate for dnsNames in secret, signed by the CA of the secret. The CA is
+// created first if the secret has none or it expires before the new certificate.
+func issueCertificates(secret *v1.Secret, dnsNames []string) error {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	ca, caKey, err := parseCA(secret)
+	if err != nil || time.Until(ca.NotAfter) < CertValidity {
+		ca, caKey, err = newCA(secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[len(dnsNames)-1]},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(CertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca, key.Public(), caKey)
+	if err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	secret.Data[v1.TLSCertKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	secret.Data[v1.TLSPrivateKeyKey] = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return nil
+}
+
+// newCA sets a new self-signed CA in secret
+func newCA(secret *v1.Secret) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "slik-webhook-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret.Data[SecretKeyCACert] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	secret.Data[SecretKeyCAKey] = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return ca, key, nil
+}
+
+// parseCA returns the CA certificate and key of secret
+func parseCA(secret *v1.Secret) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	pair, err := tls.X509KeyPair(secret.Data[SecretKeyCACert], secret.Data[SecretKeyCAKey])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok || !pair.Leaf.IsCA {
+		return nil, nil, ErrInvalidCA
+	}
+
+	return pair.Leaf, key, nil
+}
+
+// setCABundle sets ca as caBundle of the webhooks of the ConfigurationName webhook configurations. A missing
+// configuration is logged, the webhook is then not called by the api server.
+func setCABundle(client kubernetes.Interface, ca []byte) error {
+	log := zap.L().Sugar()
+
+	admission := client.AdmissionregistrationV1()
+
+	validating, err := admission.ValidatingWebhookConfigurations().Get(context.TODO(), ConfigurationName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		log.Warnf("validating webhook configuration %s not found", ConfigurationName)
+	case err != nil:
+		return err
+	default:
+		changed := false
+		for i := range validating.Webhooks {
+			if string(validating.Webhooks[i].ClientConfig.CABundle) != string(ca) {
+				validating.Webhooks[i].ClientConfig.CABundle = ca
+				changed = true
+			}
+		}
+
+		if changed {
+			if _, err := admission.ValidatingWebhookConfigurations().Update(context.TODO(), validating, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	mutating, err := admission.MutatingWebhookConfigurations().Get(context.TODO(), ConfigurationName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		log.Warnf("mutating webhook configuration %s not found", ConfigurationName)
+	case err != nil:
+		return err
+	default:
+		changed := false
+		for i := range mutating.Webhooks {
+			if string(mutating.Webhooks[i].ClientConfig.CABundle) != string(ca) {
+				mutating.Webhooks[i].ClientConfig.CABundle = ca
+				changed = true
+			}
+		}
+
+		if changed {
+			if _, err := admission.MutatingWebhookConfigurations().Update(context.TODO(), mutating, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
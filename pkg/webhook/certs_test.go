@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureCertificates(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigurationName},
+			Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "validate.sliks.hpc.vultr.com"}},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigurationName},
+			Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "default.sliks.hpc.vultr.com"}},
+		},
+	)
+
+	cert, err := EnsureCertificates(client, "slik", "slik-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := client.CoreV1().Secrets("slik").Get(t.Context(), SecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Fatalf("expected a tls secret, got %s", secret.Type)
+	}
+
+	// the serving certificate is signed by the CA for the service name the api server calls
+	block, _ := pem.Decode(secret.Data[SecretKeyCACert])
+	ca, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{DNSName: "slik-operator.slik.svc", Roots: roots}); err != nil {
+		t.Fatal(err)
+	}
+
+	validating, _ := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(t.Context(), ConfigurationName, metav1.GetOptions{})
+	mutating, _ := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(t.Context(), ConfigurationName, metav1.GetOptions{})
+	if string(validating.Webhooks[0].ClientConfig.CABundle) != string(secret.Data[SecretKeyCACert]) ||
+		string(mutating.Webhooks[0].ClientConfig.CABundle) != string(secret.Data[SecretKeyCACert]) {
+		t.Fatal("expected the CA as caBundle of the webhooks")
+	}
+
+	// a valid certificate is reused on restart
+	again, err := EnsureCertificates(client, "slik", "slik-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !again.Leaf.Equal(cert.Leaf) {
+		t.Fatal("expected the certificate to be reused")
+	}
+
+	// a new service name gets a new certificate from the same CA
+	renamed, err := EnsureCertificates(client, "slik", "slik-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renamed.Leaf.Equal(cert.Leaf) || renamed.Leaf.CheckSignatureFrom(ca) != nil {
+		t.Fatal("expected a new certificate signed by the existing CA")
+	}
+}
+
+func TestEnsureCertificatesWithoutConfigurations(t *testing.T) {
+	// the webhook configurations are installed by the helm chart, the operator starts without them
+	if _, err := EnsureCertificates(fake.NewSimpleClientset(), "slik", "slik-operator"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenewCertificates(t *testing.T) {
+	client := fake.NewSimpleClientset(&admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigurationName},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "validate.sliks.hpc.vultr.com"}},
+	})
+
+	cert, err := EnsureCertificates(client, "slik", "slik-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := NewWebhookAPI("test", "127.0.0.1", 0, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the certificate is about to expire and the caBundle was reset by a helm upgrade
+	secret, _ := client.CoreV1().Secrets("slik").Get(t.Context(), SecretName, metav1.GetOptions{})
+	delete(secret.Data, corev1.TLSCertKey)
+	if _, err := client.CoreV1().Secrets("slik").Update(t.Context(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	validating, _ := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(t.Context(), ConfigurationName, metav1.GetOptions{})
+	validating.Webhooks[0].ClientConfig.CABundle = nil
+	if _, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(t.Context(), validating, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := api.renewCertificates(client, "slik", "slik-operator"); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.cert.Load().Leaf.Equal(cert.Leaf) {
+		t.Fatal("expected the renewed certificate to be served")
+	}
+
+	validating, _ = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(t.Context(), ConfigurationName, metav1.GetOptions{})
+	if string(validating.Webhooks[0].ClientConfig.CABundle) != string(secret.Data[SecretKeyCACert]) {
+		t.Fatal("expected the caBundle to be set again")
+	}
+}
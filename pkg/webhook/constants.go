@@ -0,0 +1,29 @@
+package webhook
+
+import "time"
+
+const (
+	// SecretName of the Secret holding the self-signed CA and the serving certificate, in the operator namespace
+	SecretName string = "slik-webhook"
+
+	// ConfigurationName of the ValidatingWebhookConfiguration and MutatingWebhookConfiguration installed by
+	// the helm chart, their caBundle is set by the operator
+	ConfigurationName string = "slik"
+)
+
+const (
+	// CAValidity of the self-signed CA
+	CAValidity time.Duration = 10 * 365 * 24 * time.Hour
+
+	// CertValidity of the serving certificate, renewed once less than CertRenewBefore is left. It is checked on
+	// start and every CertRenewInterval.
+	CertValidity      time.Duration = 365 * 24 * time.Hour
+	CertRenewBefore   time.Duration = 30 * 24 * time.Hour
+	CertRenewInterval time.Duration = 24 * time.Hour
+)
+
+const (
+	// SecretKeyCACert and SecretKeyCAKey hold the CA next to the tls.crt and tls.key of a kubernetes.io/tls Secret
+	SecretKeyCACert string = "ca.crt"
+	SecretKeyCAKey  string = "ca.key"
+)
@@ -0,0 +1,11 @@
+package webhook
+
+import "errors"
+
+var (
+	// ErrInvalidCA CA of the webhook Secret that is not an ecdsa CA certificate
+	ErrInvalidCA = errors.New("invalid webhook CA")
+
+	// ErrNoAdmissionRequest admission review without a request
+	ErrNoAdmissionRequest = errors.New("admission review has no request")
+)
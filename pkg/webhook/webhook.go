@@ -0,0 +1,326 @@
+// Package webhook validates and defaults Slik objects as an admission webhook, served over tls with a
+// certificate of a self-signed CA kept in a Secret
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+	"github.com/vultr/slik/pkg/slurm"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WebhookAPI configuration for the https admission webhook
+type WebhookAPI struct {
+	Listen string
+	Port   uint16
+
+	// cert is replaced by RenewCertificates, new connections are served with the current one
+	cert atomic.Pointer[tls.Certificate]
+	app  *fiber.App
+}
+
+// NewWebhookAPI creates a new webhook server serving cert
+func NewWebhookAPI(name, listen string, port uint16, cert *tls.Certificate) (*WebhookAPI, error) {
+	w := &WebhookAPI{}
+
+	// Initialize engine
+	app := fiber.New(fiber.Config{
+		AppName:               name,
+		EnablePrintRoutes:     false,
+		Prefork:               false,
+		Concurrency:           50,
+		ServerHeader:          name,
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          30 * time.Second,
+		IdleTimeout:           30 * time.Second,
+		DisableKeepalive:      true,
+		DisableStartupMessage: true,
+	})
+
+	// body is an admission.k8s.io/v1 AdmissionReview
+	app.Post("/validate", PostValidate)
+	app.Post("/mutate", PostMutate)
+
+	w.app = app
+	w.cert.Store(cert)
+	w.Listen = listen
+	w.Port = port
+
+	return w, nil
+}
+
+// Start starts the server
+func (w *WebhookAPI) Start() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("%s:%d", w.Listen, w.Port))
+	if err != nil {
+		return err
+	}
+
+	return w.app.Listener(tls.NewListener(ln, &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return w.cert.Load(), nil
+		},
+		MinVersion: tls.VersionTLS12,
+	}))
+}
+
+// RenewCertificates runs EnsureCertificates every CertRenewInterval until ctx is done, so the certificate is
+// renewed and the caBundle of the webhook configurations restored without a restart
+func (w *WebhookAPI) RenewCertificates(ctx context.Context, client kubernetes.Interface, namespace, service string) {
+	log := zap.L().Sugar()
+
+	ticker := time.NewTicker(CertRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.renewCertificates(client, namespace, service); err != nil {
+				log.Errorf("renewing webhook certificate: %s", err)
+			}
+		}
+	}
+}
+
+func (w *WebhookAPI) renewCertificates(client kubernetes.Interface, namespace, service string) error {
+	cert, err := EnsureCertificates(client, namespace, service)
+	if err != nil {
+		return err
+	}
+
+	w.cert.Store(cert)
+
+	return nil
+}
+
+// Shutdown shuts down the server
+func (w *WebhookAPI) Shutdown() error {
+	return w.app.Shutdown()
+}
+
+// PostValidate rejects a Slik that is not valid or changes immutable fields
+func PostValidate(c *fiber.Ctx) error {
+	return review(c, validate)
+}
+
+// PostMutate sets the defaults of a Slik
+func PostMutate(c *fiber.Ctx) error {
+	return review(c, mutate)
+}
+
+// review decodes the AdmissionReview of the request and responds with the result of admit
+func review(c *fiber.Ctx, admit func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) error {
+	log := zap.L().Sugar()
+
+	var ar admissionv1.AdmissionReview
+	if err := json.Unmarshal(c.Body(), &ar); err != nil || ar.Request == nil {
+		if err == nil {
+			err = ErrNoAdmissionRequest
+		}
+
+		c.Status(fiber.StatusBadRequest)
+
+		return c.JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	resp := admit(ar.Request)
+	resp.UID = ar.Request.UID
+
+	if !resp.Allowed {
+		log.With(
+			"context", "webhook",
+			"slik", fmt.Sprintf("%s/%s", ar.Request.Namespace, ar.Request.Name),
+		).Infof("denied %s: %s", ar.Request.Operation, resp.Result.Message)
+	}
+
+	c.Status(fiber.StatusOK)
+
+	return c.JSON(admissionv1.AdmissionReview{
+		TypeMeta: ar.TypeMeta,
+		Response: resp,
+	})
+}
+
+func validate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	wl, old, err := decode(req)
+	if err != nil {
+		return denied(http.StatusBadRequest, err)
+	}
+
+	switch {
+	case req.Operation == admissionv1.Delete, wl.DeletionTimestamp != nil:
+		// finalizers are removed whatever the spec
+		return allowed()
+	case old == nil:
+		err = slurm.ValidateSlik(wl)
+	case equality.Semantic.DeepEqual(old.Spec, wl.Spec):
+		// metadata only, a Slik accepted before the webhook was installed can still be annotated
+		return allowed()
+	default:
+		err = slurm.ValidateSlikUpdate(old, wl)
+	}
+
+	if err != nil {
+		return denied(http.StatusUnprocessableEntity, err)
+	}
+
+	return allowed()
+}
+
+func mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	wl, _, err := decode(req)
+	if err != nil {
+		return denied(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Delete || wl.DeletionTimestamp != nil {
+		return allowed()
+	}
+
+	defaulted := wl.DeepCopy()
+	slurm.DefaultSlik(defaulted)
+
+	if equality.Semantic.DeepEqual(wl.Spec, defaulted.Spec) {
+		return allowed()
+	}
+
+	// the defaults are merged into the spec as sent, fields left out stay out
+	var obj struct {
+		Spec map[string]any `json:"spec"`
+	}
+
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return denied(http.StatusBadRequest, err)
+	}
+
+	if obj.Spec == nil {
+		obj.Spec = map[string]any{}
+	}
+
+	mergeDefaults(obj.Spec, unstructured(wl.Spec), unstructured(defaulted.Spec))
+
+	// add replaces the spec if it is set
+	patch, err := json.Marshal([]map[string]any{
+		{"op": "add", "path": "/spec", "value": obj.Spec},
+	})
+	if err != nil {
+		return denied(http.StatusInternalServerError, err)
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+
+	resp := allowed()
+	resp.Patch = patch
+	resp.PatchType = &patchType
+
+	return resp
+}
+
+// decode returns the Slik of the request, and the Slik it replaces for updates
+func decode(req *admissionv1.AdmissionRequest) (*v1s.Slik, *v1s.Slik, error) {
+	var wl v1s.Slik
+
+	raw := req.Object.Raw
+	if req.Operation == admissionv1.Delete {
+		raw = req.OldObject.Raw
+	}
+
+	if err := json.Unmarshal(raw, &wl); err != nil {
+		return nil, nil, err
+	}
+
+	// the namespace is not set on objects being created
+	if wl.Namespace == "" {
+		wl.Namespace = req.Namespace
+	}
+
+	if req.Operation != admissionv1.Update {
+		return &wl, nil, nil
+	}
+
+	var old v1s.Slik
+	if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+		return nil, nil, err
+	}
+
+	return &wl, &old, nil
+}
+
+// mergeDefaults sets the fields of after that differ from before in spec, creating the objects above them
+func mergeDefaults(spec, before, after map[string]any) bool {
+	changed := false
+
+	for key, value := range after {
+		if nested, ok := value.(map[string]any); ok {
+			prev, _ := before[key].(map[string]any)
+
+			child, ok := spec[key].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+			}
+
+			if mergeDefaults(child, prev, nested) {
+				spec[key] = child
+				changed = true
+			}
+
+			continue
+		}
+
+		if !equality.Semantic.DeepEqual(before[key], value) {
+			spec[key] = value
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// unstructured returns obj as the maps and values of its json
+func unstructured(obj any) map[string]any {
+	m := map[string]any{}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return m
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m
+	}
+
+	return m
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(code int32, err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Code:    code,
+		},
+	}
+}
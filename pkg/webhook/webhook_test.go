@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	v1s "github.com/vultr/slik/pkg/api/types/v1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func post(t *testing.T, path string, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	api, err := NewWebhookAPI("test", "127.0.0.1", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.UID = types.UID("42")
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  req,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := api.app.Test(httptest.NewRequest("POST", path, bytes.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ar admissionv1.AdmissionReview
+	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
+		t.Fatal(err)
+	}
+
+	if ar.Kind != "AdmissionReview" || ar.Response.UID != "42" {
+		t.Fatalf("unexpected review: %+v", ar)
+	}
+
+	return ar.Response
+}
+
+func raw(t *testing.T, obj any) runtime.RawExtension {
+	t.Helper()
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return runtime.RawExtension{Raw: data}
+}
+
+func slik(mutate func(*v1s.Slik)) *v1s.Slik {
+	wl := &v1s.Slik{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1s.SlikSpec{
+			Namespace: "default",
+			Slurmdbd:  true,
+			MariaDB:   v1s.MariaDB{StorageSize: "50G", StorageClass: "block"},
+		},
+	}
+
+	if mutate != nil {
+		mutate(wl)
+	}
+
+	return wl
+}
+
+func TestValidate(t *testing.T) {
+	create := post(t, "/validate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    raw(t, slik(nil)),
+	})
+	if !create.Allowed {
+		t.Fatalf("expected a valid Slik to be allowed: %+v", create.Result)
+	}
+
+	conflict := post(t, "/validate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    raw(t, slik(func(wl *v1s.Slik) { wl.Spec.Slurmdbd = false; wl.Spec.Slurmrestd = true })),
+	})
+	if conflict.Allowed || conflict.Result.Code != 422 {
+		t.Fatalf("expected slurmrestd without slurmdbd to be denied: %+v", conflict)
+	}
+
+	immutable := post(t, "/validate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		Object:    raw(t, slik(func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageClass = "other" })),
+		OldObject: raw(t, slik(nil)),
+	})
+	if immutable.Allowed {
+		t.Fatal("expected a storage class change to be denied")
+	}
+
+	// a Slik accepted before the webhook can still be annotated and deleted
+	invalid := slik(func(wl *v1s.Slik) { wl.Spec.MariaDB.StorageSize = "10G" })
+	annotated := invalid.DeepCopy()
+	annotated.Annotations = map[string]string{"slik.vultr.com/dry-run": "true"}
+
+	metadata := post(t, "/validate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		Object:    raw(t, annotated),
+		OldObject: raw(t, invalid),
+	})
+	if !metadata.Allowed {
+		t.Fatalf("expected a metadata update to be allowed: %+v", metadata.Result)
+	}
+
+	deleting := invalid.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Spec.Namespace = "other"
+
+	finalizers := post(t, "/validate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		Object:    raw(t, deleting),
+		OldObject: raw(t, invalid),
+	})
+	if !finalizers.Allowed {
+		t.Fatalf("expected a Slik being deleted to be allowed: %+v", finalizers.Result)
+	}
+}
+
+func TestMutate(t *testing.T) {
+	// only the defaults are added to the spec as sent
+	resp := post(t, "/mutate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "hpc",
+		Object:    runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"test"},"spec":{"login":{"enabled":true}}}`)},
+	})
+	if !resp.Allowed || resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch {
+		t.Fatalf("expected a json patch: %+v", resp)
+	}
+
+	var patch []struct {
+		Op    string         `json:"op"`
+		Path  string         `json:"path"`
+		Value map[string]any `json:"value"`
+	}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"drain":{"timeoutSeconds":3600},"login":{"enabled":true,"replicas":1,"serviceType":"LoadBalancer"},"namespace":"hpc"}`
+	if got, _ := json.Marshal(patch[0].Value); len(patch) != 1 || patch[0].Path != "/spec" || string(got) != want {
+		t.Fatalf("unexpected patch: %s", resp.Patch)
+	}
+
+	// nothing to default
+	resp = post(t, "/mutate", &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    raw(t, slik(func(wl *v1s.Slik) { wl.Spec.Drain.TimeoutSeconds = 60 })),
+	})
+	if !resp.Allowed || resp.Patch != nil {
+		t.Fatalf("expected no patch: %s", resp.Patch)
+	}
+}
+
+func TestReviewWithoutRequest(t *testing.T) {
+	api, err := NewWebhookAPI("test", "127.0.0.1", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := api.app.Test(httptest.NewRequest("POST", "/validate", bytes.NewReader([]byte(`{}`))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected a bad request, got %d", resp.StatusCode)
+	}
+}